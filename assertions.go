@@ -1,6 +1,7 @@
 package prettytest
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"reflect"
@@ -18,9 +19,11 @@ type Assertion struct {
 }
 
 func (assertion *Assertion) fail() {
-	assertion.Passed = false
-	assertion.testFunc.Status = STATUS_FAIL
-	logError(&Error{assertion.suite, assertion.testFunc, assertion})
+	assertion.testFunc.record(func() {
+		assertion.Passed = false
+		assertion.testFunc.Status = STATUS_FAIL
+		logError(&Error{assertion.suite, assertion.testFunc, assertion})
+	})
 }
 
 // Not asserts the given assertion is false.
@@ -114,12 +117,63 @@ func (s *Suite) Pending() {
 	s.currentTestFunc().Status = STATUS_PENDING
 }
 
+// skipSignal is raised by Skip to stop the current test method (or
+// BeforeAll) and recovered by the runner.
+type skipSignal struct{}
+
+// Skip marks the current test function as skipped with the given
+// reason and stops its execution. When called from BeforeAll, every
+// test of the suite is skipped.
+func (s *Suite) Skip(reason string) {
+	if current := s.current(); current != nil {
+		current.Status = STATUS_SKIPPED
+		current.SkipReason = reason
+	} else {
+		s.skipReason = reason
+	}
+	panic(skipSignal{})
+}
+
+// SkipIf skips the current test function if cond is true.
+func (s *Suite) SkipIf(cond bool, reason string) {
+	if cond {
+		s.Skip(reason)
+	}
+}
+
+// SkipUnlessEnv skips the current test function unless the given
+// environment variable is set to a non-empty value.
+func (s *Suite) SkipUnlessEnv(name string) {
+	if os.Getenv(name) == "" {
+		s.Skip(fmt.Sprintf("$%s is not set", name))
+	}
+}
+
+// SkipOnShort skips the current test function when go test is run
+// with the -short flag.
+func (s *Suite) SkipOnShort() {
+	if f := flag.Lookup("test.short"); f != nil && f.Value.String() == "true" {
+		s.Skip("Skipped in short mode")
+	}
+}
+
 // MustFail marks the current test function as an expected failure.
 func (s *Suite) MustFail() {
 	s.currentTestFunc().mustFail = true
 }
 
-// Failed checks if the test function has failed.
+// Failed checks if the test function has failed or timed out.
 func (s *Suite) Failed() bool {
-	return s.currentTestFunc().Status == STATUS_FAIL
+	return failed(s.currentTestFunc())
+}
+
+// Record records the outcome of a check made by a helper package
+// (see the mock subpackage) as an assertion made at filename:line.
+func (s *Suite) Record(passed bool, filename string, line int, message string) *Assertion {
+	assertion := s.setup(message, []string{})
+	assertion.Filename, assertion.Line = filename, line
+	if !passed {
+		assertion.fail()
+	}
+	return assertion
 }
@@ -0,0 +1,208 @@
+package prettytest
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+const benchmarkPrefix = "Benchmark"
+
+var (
+	benchmarkType = reflect.TypeOf((*B)(nil))
+	testingBType  = reflect.TypeOf((*testing.B)(nil))
+)
+
+// takesBenchmark checks if method takes a single *B, or the
+// *testing.B it is easily confused with, so that helpers like
+// BenchmarkReport(n int) are not mistaken for benchmarks.
+func takesBenchmark(method reflect.Method) bool {
+	return method.Type.NumIn() == 2 && (method.Type.In(1) == benchmarkType || method.Type.In(1) == testingBType)
+}
+
+// B is passed to the Benchmark methods of a suite. It embeds
+// *testing.B, so b.N and the timer methods work as usual.
+type B struct {
+	*testing.B
+	timerOn                 bool
+	start                   time.Time
+	startAllocs, startBytes uint64
+	duration                time.Duration
+	netAllocs, netBytes     uint64
+}
+
+// StartTimer starts timing the benchmark, like testing.B.StartTimer.
+func (b *B) StartTimer() {
+	b.B.StartTimer()
+	if !b.timerOn {
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+		b.startAllocs, b.startBytes = stats.Mallocs, stats.TotalAlloc
+		b.start = time.Now()
+		b.timerOn = true
+	}
+}
+
+// StopTimer stops timing the benchmark, like testing.B.StopTimer.
+func (b *B) StopTimer() {
+	if b.timerOn {
+		b.duration += time.Since(b.start)
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+		b.netAllocs += stats.Mallocs - b.startAllocs
+		b.netBytes += stats.TotalAlloc - b.startBytes
+		b.timerOn = false
+	}
+	b.B.StopTimer()
+}
+
+// ResetTimer zeroes the elapsed time and the allocations of the
+// benchmark, like testing.B.ResetTimer.
+func (b *B) ResetTimer() {
+	if b.timerOn {
+		var stats runtime.MemStats
+		runtime.ReadMemStats(&stats)
+		b.startAllocs, b.startBytes = stats.Mallocs, stats.TotalAlloc
+		b.start = time.Now()
+	}
+	b.duration, b.netAllocs, b.netBytes = 0, 0, 0
+	b.B.ResetTimer()
+}
+
+// result returns the figures measured while the timer was running,
+// as testing.B does.
+func (b *B) result() testing.BenchmarkResult {
+	return testing.BenchmarkResult{N: b.N, T: b.duration, MemAllocs: b.netAllocs, MemBytes: b.netBytes}
+}
+
+// BenchmarkFormatter is implemented by formatters that can print
+// the results of the Benchmark methods run by RunBenchmarks.
+// Formatters that don't implement it only get the status of each
+// benchmark through PrintStatus.
+type BenchmarkFormatter interface {
+	PrintBenchmark(testFunc *TestFunc, result testing.BenchmarkResult)
+}
+
+// RunBenchmarks runs the Benchmark methods of the suites as
+// sub-benchmarks of b. Benchmark methods take a *B argument; they
+// share the BeforeAll/AfterAll hooks with the test methods of the
+// suite, and Before/After are called around each invocation.
+//
+// Before and After are run with the timer stopped, so that, like the
+// figures printed by go test -bench, those printed by the formatter
+// only measure the method while its timer runs.
+func RunBenchmarks(b *testing.B, suites ...tCatcher) {
+	runBenchmarks(b, new(TDDFormatter), suites...)
+}
+
+// RunBenchmarksWithFormatter runs the Benchmark methods of the
+// suites using the given formatter.
+func RunBenchmarksWithFormatter(b *testing.B, formatter Formatter, suites ...tCatcher) {
+	runBenchmarks(b, formatter, suites...)
+}
+
+func runBenchmarks(b *testing.B, formatter Formatter, suites ...tCatcher) {
+	report := new(FinalReport)
+	ErrorLog = make([]*Error, 0)
+	resetFixtures()
+
+	envErr := applyEnv()
+	for i, s := range suites {
+		prepare(b, s)
+		plan := newSuitePlan(s, formatter)
+		if i == 0 {
+			plan.checkOptions(envErr)
+		}
+		formatter.PrintSuiteInfo(s.suite())
+		plan.runBenchmarks(b, formatter, report)
+
+		formatter.PrintErrorLog(ErrorLog)
+		formatter.PrintFinalReport(report)
+	}
+}
+
+func (plan *suitePlan) runBenchmarks(b *testing.B, formatter Formatter, report *FinalReport) {
+	for _, error := range plan.errors {
+		logError(error)
+		plan.report(b, formatter, report, error.TestFunc)
+	}
+
+	if plan.runInjections(b, formatter, report) && plan.runSuiteHooks(b, formatter, report, plan.beforeAll) {
+		for _, method := range plan.benchmarks {
+			var testFunc *TestFunc
+			var result testing.BenchmarkResult
+			b.Run(method.Name, func(b *testing.B) {
+				testFunc, result = plan.runBenchmark(method, b)
+			})
+			// testFunc is nil when the benchmark is excluded by
+			// -test.bench.
+			if testFunc != nil {
+				plan.reportBenchmark(b, formatter, report, testFunc, result)
+			}
+		}
+		plan.runSuiteHooks(b, formatter, report, plan.afterAll)
+	}
+	plan.runSuiteCleanups(b, formatter, report, nil)
+}
+
+// runBenchmark runs a single invocation of a Benchmark method,
+// surrounded by the per-test hooks and cleanups.
+func (plan *suitePlan) runBenchmark(method reflect.Method, b *testing.B) (*TestFunc, testing.BenchmarkResult) {
+	s := plan.catcher.suite()
+	testFunc := s.startTestFunc(method.Name)
+	defer s.endTestFunc()
+	info := newTestInfo(testFunc)
+	b.ReportAllocs()
+
+	bb := &B{B: b}
+	args := []reflect.Value{reflect.ValueOf(plan.catcher), reflect.ValueOf(bb)}
+	body := func() { method.Func.Call(args) }
+	b.StopTimer()
+	r := protect(func() { plan.call(plan.before, info) })
+	if r == nil {
+		info.start = time.Now()
+		bb.StartTimer()
+		r = protect(body)
+		bb.StopTimer()
+		testFunc.Duration = time.Since(info.start)
+	}
+	if r != nil {
+		testFunc.failWith(r.filename, r.line, r.message())
+	}
+	if r := protect(func() { plan.call(plan.after, info) }); r != nil {
+		testFunc.failWith(r.filename, r.line, r.message())
+	}
+	runCleanups(testFunc.cleanups, testFunc)
+
+	if failed(testFunc) {
+		b.Fail()
+	}
+	return testFunc, bb.result()
+}
+
+func (plan *suitePlan) reportBenchmark(b *testing.B, formatter Formatter, report *FinalReport, testFunc *TestFunc, result testing.BenchmarkResult) {
+	if testFunc.Status == STATUS_NO_ASSERTIONS {
+		testFunc.Status = STATUS_PASS
+	}
+	benchmarkFormatter, ok := formatter.(BenchmarkFormatter)
+	if !ok || failed(testFunc) {
+		plan.report(b, formatter, report, testFunc)
+		return
+	}
+	report.count(testFunc)
+	benchmarkFormatter.PrintBenchmark(testFunc, result)
+}
+
+func (formatter *TDDFormatter) PrintBenchmark(testFunc *TestFunc, result testing.BenchmarkResult) {
+	fmt.Printf(formatTag+"%-29s %10d %12d ns/op %10d B/op %8d allocs/op\n",
+		labelPASS, testFunc.Name, result.N, result.NsPerOp(), result.AllocedBytesPerOp(), result.AllocsPerOp())
+}
+
+func (formatter *BDDFormatter) PrintBenchmark(testFunc *TestFunc, result testing.BenchmarkResult) {
+	text := strings.Replace(testFunc.Name, "_", " ", -1)
+	fmt.Printf("- %s\t(%d ns/op, %d B/op, %d allocs/op)\n",
+		green(text), result.NsPerOp(), result.AllocedBytesPerOp(), result.AllocsPerOp())
+}
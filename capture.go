@@ -0,0 +1,96 @@
+package prettytest
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+var logMutex sync.Mutex
+
+// Log formats its arguments like fmt.Sprint and records the text in
+// the log of the current test function. Formatters print the log of
+// failing tests only, unless -pt.v is given. Outside of test methods
+// the text is printed immediately.
+func (s *Suite) Log(args ...interface{}) {
+	s.log(fmt.Sprint(args...))
+}
+
+// Logf formats its arguments like fmt.Sprintf and records the text
+// in the log of the current test function.
+func (s *Suite) Logf(format string, args ...interface{}) {
+	s.log(fmt.Sprintf(format, args...))
+}
+
+func (s *Suite) log(text string) {
+	if _, filename, line, ok := runtime.Caller(2); ok {
+		text = fmt.Sprintf("%s:%d: %s", filepath.Base(filename), line, text)
+	}
+	current := s.current()
+	if current == nil {
+		fmt.Println(text)
+		return
+	}
+	current.appendLog(text)
+}
+
+func (testFunc *TestFunc) appendLog(text string) {
+	logMutex.Lock()
+	defer logMutex.Unlock()
+	testFunc.Logs = append(testFunc.Logs, strings.Split(strings.TrimRight(text, "\n"), "\n")...)
+}
+
+// capture redirects os.Stdout, os.Stderr and the standard logger to
+// the log of a test function while it runs (see -pt.capture).
+type capture struct {
+	testFunc       *TestFunc
+	stdout, stderr *os.File
+	logger         io.Writer
+	w              *os.File
+	output         bytes.Buffer
+	done           chan bool
+}
+
+func startCapture(testFunc *TestFunc) *capture {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil
+	}
+	c := &capture{
+		testFunc: testFunc,
+		stdout:   os.Stdout,
+		stderr:   os.Stderr,
+		logger:   log.Writer(),
+		w:        w,
+		done:     make(chan bool),
+	}
+	go func() {
+		io.Copy(&c.output, r)
+		r.Close()
+		c.done <- true
+	}()
+	os.Stdout, os.Stderr = w, w
+	log.SetOutput(w)
+	return c
+}
+
+// stop restores the original outputs and appends what was captured
+// to the log of the test function.
+func (c *capture) stop() {
+	if c == nil {
+		return
+	}
+	os.Stdout, os.Stderr = c.stdout, c.stderr
+	log.SetOutput(c.logger)
+	c.w.Close()
+	<-c.done
+	if c.output.Len() > 0 {
+		c.testFunc.appendLog(c.output.String())
+	}
+}
@@ -0,0 +1,119 @@
+package prettytest
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"strings"
+)
+
+var unsafePathChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// recovered describes a panic caught while running a test method or
+// a hook.
+type recovered struct {
+	value    interface{}
+	filename string
+	line     int
+	stack    []byte
+}
+
+func (r *recovered) message() string {
+	return fmt.Sprintf("Panic: %v\n%s", r.value, r.stack)
+}
+
+// protect calls fn and recovers from any panic it raises. Stopping
+// a test with Skip is not reported as a panic.
+func protect(fn func()) (result *recovered) {
+	defer func() {
+		if value := recover(); value != nil {
+			if _, ok := value.(skipSignal); ok {
+				return
+			}
+			filename, line := panicLocation()
+			result = &recovered{value, filename, line, trimStack(debug.Stack())}
+		}
+	}()
+	fn()
+	return nil
+}
+
+// trimStack keeps the part of a goroutine stack trace between the
+// panic, if any, and the runner frames.
+func trimStack(stack []byte) []byte {
+	lines := strings.Split(string(stack), "\n")
+	start, end := 0, len(lines)
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "panic("):
+			start = i
+		case strings.HasPrefix(line, "reflect.Value.") || strings.HasPrefix(line, "github.com/remogatto/prettytest.protect("):
+			end = i
+		}
+		if end < len(lines) {
+			break
+		}
+	}
+	return []byte(strings.Join(lines[start:end], "\n"))
+}
+
+// panicLocation returns the location of the first frame below
+// runtime.gopanic, i.e. the code that panicked. It must be called by
+// the deferred function that recovers.
+func panicLocation() (string, int) {
+	pc := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pc[:runtime.Callers(2, pc)])
+	panicking := false
+	for {
+		frame, more := frames.Next()
+		if panicking && !strings.HasPrefix(frame.Function, "runtime.") {
+			return frame.File, frame.Line
+		}
+		if frame.Function == "runtime.gopanic" {
+			panicking = true
+		}
+		if !more {
+			return "", 0
+		}
+	}
+}
+
+// Cleanup registers fn to be called when the current test method
+// completes, or when the suite completes if called from BeforeAll.
+// Cleanup functions are called in last added, first called order,
+// even if the test method panics or fails.
+func (s *Suite) Cleanup(fn func()) {
+	if current := s.current(); current != nil {
+		current.cleanups = append(current.cleanups, fn)
+	} else {
+		s.cleanups = append(s.cleanups, fn)
+	}
+}
+
+// TempDir creates a new directory for the current test method (or
+// for the suite, if called from BeforeAll) and registers its removal
+// with Cleanup.
+func (s *Suite) TempDir() string {
+	name := s.Name
+	if current := s.current(); current != nil {
+		name += "-" + current.Name
+	}
+	dir, err := os.MkdirTemp("", "prettytest-"+unsafePathChars.ReplaceAllString(name, "_")+"-")
+	if err != nil {
+		panic(err)
+	}
+	s.Cleanup(func() { os.RemoveAll(dir) })
+	return dir
+}
+
+// runCleanups calls the given cleanup functions in reverse order.
+// Panics are reported as failures of testFunc.
+func runCleanups(cleanups []func(), testFunc *TestFunc) {
+	for i := len(cleanups) - 1; i >= 0; i-- {
+		if r := protect(cleanups[i]); r != nil {
+			testFunc.failWith(r.filename, r.line, r.message())
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package prettytest
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	testToRun     = flags.String("pt.run", "", "[prettytest] comma-separated regular expressions, or Suite/Test paths, selecting the tests and examples to run")
+	testsToSkip   = flags.String("pt.skip", "", "[prettytest] comma-separated regular expressions, or Suite/Test paths, selecting the tests to skip")
+	testAt        = flags.String("pt.at", "", "[prettytest] run only the test declared at file:line (e.g. parser_test.go:42)")
+	tagsToRun     = flags.String("pt.tags", "", "[prettytest] tag expression (e.g. \"integration && !slow\") selecting the tests to run")
+	tagsToSkip    = flags.String("pt.skip-tags", "", "[prettytest] tag expression selecting the tests to skip")
+	shuffle       = flags.String("pt.shuffle", "off", "[prettytest] randomize the order of suites and tests: on, off or the seed to reuse")
+	repeatCount   = flags.Int("pt.count", 1, "[prettytest] run each test N times, reporting tests with mixed outcomes as flaky")
+	untilFail     = flags.Bool("pt.until-fail", false, "[prettytest] repeat each test until it fails (at most -pt.count times, or 100)")
+	maxRetries    = flags.Int("pt.retries", 0, "[prettytest] retry a failing test up to N times before marking it as failed")
+	testTimeout   = flags.Duration("pt.timeout", 0, "[prettytest] time limit for each test method, 0 means no limit")
+	verbose       = flags.Bool("pt.v", false, "[prettytest] print the log of every test, not only of the failing ones")
+	captureOutput = flags.Bool("pt.capture", false, "[prettytest] capture stdout, stderr and the standard logger in the log of each test")
+	leaks         = flags.String("pt.leaks", "off", "[prettytest] check for leaked goroutines after each test or suite: off, test or suite")
+	leakGrace     = flags.Duration("pt.leak-grace", 100*time.Millisecond, "[prettytest] time left to goroutines to exit before they are reported as leaked")
+	propertyRuns  = flags.Int("pt.property-runs", 100, "[prettytest] number of random inputs checked by each Property assertion")
+	propertySeed  = flags.Int64("pt.property-seed", 0, "[prettytest] seed of the inputs generated by Property assertions, 0 means random")
+	noFocus       = flags.Bool("pt.no-focus", false, "[prettytest] fail the run if some tests are focused instead of running only those")
+)
+
+// envPrefix is the prefix of the environment variables setting the
+// prettytest options.
+const envPrefix = "PRETTYTEST_"
+
+var (
+	envMu  sync.Mutex
+	envSet = make(map[string]bool)
+)
+
+// envName returns the environment variable setting the named option,
+// e.g. PRETTYTEST_SKIP_TAGS for pt.skip-tags.
+func envName(name string) string {
+	name = strings.TrimPrefix(name, "pt.")
+	return envPrefix + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(name))
+}
+
+// applyEnv sets the prettytest options from the environment, e.g.
+// PRETTYTEST_RUN=TestParse for -pt.run=TestParse. This is the only way
+// to configure the tests on targets like Android, where the test
+// binary can't be given flags. Options given as flags take precedence.
+func applyEnv() error {
+	envMu.Lock()
+	defer envMu.Unlock()
+	fromFlags := make(map[string]bool)
+	flags.Visit(func(f *flag.Flag) { fromFlags[f.Name] = true })
+	var errors []string
+	flags.VisitAll(func(f *flag.Flag) {
+		if !strings.HasPrefix(f.Name, "pt.") || fromFlags[f.Name] {
+			return
+		}
+		value, ok := os.LookupEnv(envName(f.Name))
+		if !ok {
+			if envSet[f.Name] {
+				f.Value.Set(f.DefValue)
+				delete(envSet, f.Name)
+			}
+			return
+		}
+		if err := f.Value.Set(value); err != nil {
+			errors = append(errors, fmt.Sprintf("Invalid value %q for %s: %s", value, envName(f.Name), err))
+			return
+		}
+		envSet[f.Name] = true
+	})
+	if len(errors) > 0 {
+		return fmt.Errorf("%s", strings.Join(errors, "\n"))
+	}
+	return nil
+}
+
+// checkOptions records in plan the errors in the environment, given
+// by applyEnv, and in the options selecting the tests. It is called
+// on the first plan of a run, so that they are reported once.
+func (plan *suitePlan) checkOptions(envErr error) {
+	if envErr != nil {
+		plan.addError("Environment", "", 0, envErr.Error())
+	}
+	if _, err := matchTags(nil); err != nil {
+		plan.addError("Tags", "", 0, err.Error())
+	}
+	if _, err := matchName("", ""); err != nil {
+		plan.addError("Filter", "", 0, err.Error())
+	}
+}
+
+func filterMethod(suite, name string) bool {
+	ok, _ := matchName(suite, name)
+	return ok
+}
@@ -0,0 +1,56 @@
+package prettytest
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+)
+
+type contextKey int
+
+const testInfoKey contextKey = 0
+
+// Context returns the context of the current test method. It carries
+// the TestInfo of the test (see FromContext) and it is cancelled
+// when the method finishes, times out or the run is interrupted,
+// before After and the cleanup functions run. When called from
+// BeforeAll it returns the suite context, cancelled when the suite
+// completes.
+func (s *Suite) Context() context.Context {
+	if current := s.current(); current != nil && current.ctx != nil {
+		return current.ctx
+	}
+	if s.ctx != nil {
+		return s.ctx
+	}
+	return context.Background()
+}
+
+// FromContext returns the TestInfo carried by a context obtained
+// from Suite.Context, e.g. to label log lines with the test name.
+func FromContext(ctx context.Context) (*TestInfo, bool) {
+	info, ok := ctx.Value(testInfoKey).(*TestInfo)
+	return info, ok
+}
+
+var (
+	interruptOnce = new(sync.Once)
+	interrupted   context.Context
+)
+
+// interruptContext returns a context cancelled on SIGINT. The context
+// is shared by every run of the process, so that once interrupted the
+// remaining runs stop as well. Only the first interrupt is caught: a
+// second one kills the process as usual.
+func interruptContext() context.Context {
+	interruptOnce.Do(func() {
+		var stop context.CancelFunc
+		interrupted, stop = signal.NotifyContext(context.Background(), os.Interrupt)
+		go func() {
+			<-interrupted.Done()
+			stop()
+		}()
+	})
+	return interrupted
+}
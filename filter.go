@@ -0,0 +1,140 @@
+package prettytest
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// matchName reports whether the named test of suite is selected by
+// the -pt.run and -pt.skip patterns.
+func matchName(suite, name string) (bool, error) {
+	ok, err := matchPatterns(*testToRun, suite, name)
+	if err != nil || !ok {
+		return false, err
+	}
+	if *testsToSkip != "" {
+		skip, err := matchPatterns(*testsToSkip, suite, name)
+		if err != nil || skip {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// matchPatterns reports whether one of the comma-separated patterns
+// matches the named test of suite. A pattern is either a regular
+// expression matched against the name of the test or, like with go
+// test -run, a slash-separated list of expressions matched against
+// the name of the suite and the levels of the name of the test, e.g.
+// "parserSuite/TestParse" or "specSuite/Parser/with empty input".
+func matchPatterns(patterns, suite, name string) (bool, error) {
+	for _, pattern := range strings.Split(patterns, ",") {
+		elems := strings.Split(pattern, "/")
+		targets := []string{name}
+		if len(elems) > 1 {
+			targets = append([]string{suite}, strings.Split(name, "/")...)
+		}
+		matched := len(elems) <= len(targets)
+		for i := 0; matched && i < len(elems); i++ {
+			ok, err := regexp.MatchString(elems[i], targets[i])
+			if err != nil {
+				return false, fmt.Errorf("Invalid test pattern %q: %s", pattern, err)
+			}
+			matched = ok
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// selectAt keeps in plans only the tests declared around the
+// file:line location given with -pt.at, e.g. parser_test.go:42. The
+// file may be given by a path relative to any directory. The
+// selected test is the innermost test method or spec whose
+// declaration spans the line.
+func selectAt(plans []*suitePlan) error {
+	if *testAt == "" {
+		return nil
+	}
+	i := strings.LastIndex(*testAt, ":")
+	if i < 0 {
+		return fmt.Errorf("Invalid location %q, expected file:line", *testAt)
+	}
+	file, lineText := filepath.Clean((*testAt)[:i]), (*testAt)[i+1:]
+	line, err := strconv.Atoi(lineText)
+	if err != nil {
+		return fmt.Errorf("Invalid location %q, expected file:line", *testAt)
+	}
+
+	extents := make(map[string]map[int]int)
+	bestFile, bestStart := "", 0
+	for _, plan := range plans {
+		for _, tc := range plan.methods {
+			filename, start := tc.location()
+			if start > line || start <= bestStart || !sameFile(filename, file) {
+				continue
+			}
+			if declarationEnd(extents, filename, start) >= line {
+				bestFile, bestStart = filename, start
+			}
+		}
+	}
+	if bestFile == "" {
+		return fmt.Errorf("No test is declared at %s", *testAt)
+	}
+	for _, plan := range plans {
+		methods := plan.methods[:0]
+		for _, tc := range plan.methods {
+			if filename, start := tc.location(); filename == bestFile && start == bestStart {
+				methods = append(methods, tc)
+			}
+		}
+		plan.methods = methods
+	}
+	return nil
+}
+
+func sameFile(filename, file string) bool {
+	return filename == file || strings.HasSuffix(filename, string(filepath.Separator)+file)
+}
+
+// declarationEnd returns the last line of the function or call
+// starting at line start of filename. If the file can't be parsed,
+// the declaration is assumed to extend to the end of the file.
+func declarationEnd(extents map[string]map[int]int, filename string, start int) int {
+	ends, ok := extents[filename]
+	if !ok {
+		ends = make(map[int]int)
+		extents[filename] = ends
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, filename, nil, 0)
+		if err != nil {
+			ends = nil
+			extents[filename] = nil
+		} else {
+			ast.Inspect(f, func(n ast.Node) bool {
+				switch n.(type) {
+				case *ast.FuncDecl, *ast.CallExpr:
+					first, last := fset.Position(n.Pos()).Line, fset.Position(n.End()).Line
+					if last > ends[first] {
+						ends[first] = last
+					}
+				}
+				return true
+			})
+		}
+	}
+	if ends == nil {
+		return math.MaxInt32
+	}
+	return ends[start]
+}
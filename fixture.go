@@ -0,0 +1,136 @@
+package prettytest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
+)
+
+var (
+	fixtureMu       sync.Mutex
+	fixtureCache    map[string][]byte
+	fixtureDecoders = map[string]func(data []byte, v interface{}) error{
+		".json": json.Unmarshal,
+	}
+)
+
+// RegisterFixtureDecoder registers the function decoding the
+// fixtures with the given extension (e.g. ".yaml"), so that other
+// formats can be supported without adding dependencies:
+//
+//	prettytest.RegisterFixtureDecoder(".yaml", yaml.Unmarshal)
+func RegisterFixtureDecoder(ext string, decode func(data []byte, v interface{}) error) {
+	fixtureMu.Lock()
+	defer fixtureMu.Unlock()
+	fixtureDecoders[ext] = decode
+}
+
+// resetFixtures empties the fixture cache at the beginning of a run.
+func resetFixtures() {
+	fixtureMu.Lock()
+	defer fixtureMu.Unlock()
+	fixtureCache = make(map[string][]byte)
+}
+
+// readFixture returns the content of the file at path, reading it
+// only once per run.
+func readFixture(path string) ([]byte, error) {
+	fixtureMu.Lock()
+	defer fixtureMu.Unlock()
+	if data, ok := fixtureCache[path]; ok {
+		return data, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if fixtureCache == nil {
+		fixtureCache = make(map[string][]byte)
+	}
+	fixtureCache[path] = data
+	return data, nil
+}
+
+// Fixture loads the file testdata/<suite name>/<name> into v. If v
+// is a *string or a *[]byte it receives the content of the file,
+// otherwise the content is decoded according to the extension of
+// the file: JSON is supported out of the box, other formats can be
+// added with RegisterFixtureDecoder.
+//
+// Files ending in .tmpl are expanded with text/template, using the
+// first of data, if any, as the data of the template, and then
+// decoded according to the extension preceding .tmpl (e.g.
+// users.json.tmpl is decoded as JSON).
+//
+// The assertion fails if the fixture is missing or malformed.
+func (s *Suite) Fixture(name string, v interface{}, data ...interface{}) *Assertion {
+	assertion := s.setup("", []string{})
+	var templateData interface{}
+	if len(data) > 0 {
+		templateData = data[0]
+	}
+	if err := loadFixture(s, name, v, templateData); err != nil {
+		assertion.ErrorMessage = err.Error()
+		assertion.fail()
+	}
+	return assertion
+}
+
+// loadFixture reads the named fixture of s, expanding it with data
+// if it is a template, and decodes it into v.
+func loadFixture(s *Suite, name string, v interface{}, data interface{}) error {
+	path := filepath.Join("testdata", s.Name, name)
+	content, err := readFixture(path)
+	if err != nil {
+		return fmt.Errorf("Fixture %s not found: %s", path, err)
+	}
+
+	ext := filepath.Ext(name)
+	if ext == ".tmpl" {
+		if content, err = expandFixture(path, content, data); err != nil {
+			return fmt.Errorf("Fixture %s is malformed: %s", path, err)
+		}
+		ext = filepath.Ext(strings.TrimSuffix(name, ext))
+	}
+
+	if err := decodeFixture(ext, content, v); err != nil {
+		return fmt.Errorf("Fixture %s is malformed: %s", path, err)
+	}
+	return nil
+}
+
+func expandFixture(path string, content []byte, data interface{}) ([]byte, error) {
+	tmpl, err := template.New(filepath.Base(path)).Option("missingkey=error").Parse(string(content))
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeFixture(ext string, content []byte, v interface{}) error {
+	switch target := v.(type) {
+	case *string:
+		*target = string(content)
+		return nil
+	case *[]byte:
+		// content may be cached: hand out a copy the test can modify.
+		*target = append([]byte(nil), content...)
+		return nil
+	}
+	fixtureMu.Lock()
+	decode, ok := fixtureDecoders[ext]
+	fixtureMu.Unlock()
+	if !ok {
+		return fmt.Errorf("no decoder registered for %q files, use a *string or a *[]byte", ext)
+	}
+	return decode(content, v)
+}
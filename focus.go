@@ -0,0 +1,54 @@
+package prettytest
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// focusPrefix marks focused test methods, e.g. FTestParse with
+// TDDFormatter or FShould_parse with BDDFormatter. Specs are focused
+// by declaring them with FIt or inside FDescribe. When some tests of
+// a run are focused, only those are run: the others, in every suite,
+// are left out and counted in FinalReport.SkippedByFocus. With
+// -pt.no-focus, focused tests are reported as failures and every test
+// is run, to keep focused tests from being committed by mistake.
+const focusPrefix = "F"
+
+// focusedMethod checks if name is a focused test method according
+// to pattern.
+func focusedMethod(pattern *regexp.Regexp, name string) bool {
+	return strings.HasPrefix(name, focusPrefix) && pattern.MatchString(name[len(focusPrefix):])
+}
+
+// applyFocus removes the tests which are not focused from plans, if
+// some are, and returns how many were removed. With -pt.no-focus,
+// the focused tests are recorded as plan errors instead.
+func applyFocus(plans []*suitePlan) (focused bool, skipped int) {
+	for _, plan := range plans {
+		for _, tc := range plan.methods {
+			if tc.focused {
+				focused = true
+				if *noFocus {
+					filename, line := tc.location()
+					plan.addError(tc.name, filename, line, fmt.Sprintf("Focused test %s is not allowed with -pt.no-focus", tc.name))
+				}
+			}
+		}
+	}
+	if !focused || *noFocus {
+		return focused, 0
+	}
+	for _, plan := range plans {
+		methods := plan.methods[:0]
+		for _, tc := range plan.methods {
+			if tc.focused {
+				methods = append(methods, tc)
+			} else {
+				skipped++
+			}
+		}
+		plan.methods = methods
+	}
+	return focused, skipped
+}
@@ -14,6 +14,9 @@ var (
 	labelPASS         = green("OK")
 	labelPENDING      = yellow("PE")
 	labelNOASSERTIONS = yellow("NA")
+	labelSKIPPED      = yellow("SK")
+	labelFLAKY        = red("FL")
+	labelTIMEOUT      = red("TO")
 )
 
 func green(text string) string {
@@ -29,11 +32,104 @@ func yellow(text string) string {
 }
 
 type FinalReport struct {
-	Passed, Failed, ExpectedFailures, Pending, NoAssertions int
+	Passed, Failed, ExpectedFailures, Pending, NoAssertions, Skipped, Flaky, TimedOut int
+
+	// RunStats holds the outcomes of the tests that were run more
+	// than once (see -pt.count, -pt.until-fail and -pt.retries).
+	RunStats []*RunStats
+
+	// Shuffled is true when the tests were run in random order
+	// using Seed (see -pt.shuffle).
+	Shuffled bool
+	Seed     int64
+
+	// Interrupted is true when the run was stopped by SIGINT.
+	Interrupted bool
+
+	// SkippedByFocus is the number of tests left out because other
+	// tests were focused with the F method prefix (e.g. FTestParse),
+	// FIt or FDescribe.
+	SkippedByFocus int
 }
 
 func (r *FinalReport) Total() int {
-	return r.Passed + r.Failed + r.ExpectedFailures + r.Pending + r.NoAssertions
+	return r.Passed + r.Failed + r.ExpectedFailures + r.Pending + r.NoAssertions + r.Skipped + r.Flaky + r.TimedOut
+}
+
+func (r *FinalReport) count(testFunc *TestFunc) {
+	switch testFunc.Status {
+	case STATUS_PASS:
+		r.Passed++
+	case STATUS_FAIL:
+		r.Failed++
+	case STATUS_MUST_FAIL:
+		r.ExpectedFailures++
+	case STATUS_PENDING:
+		r.Pending++
+	case STATUS_NO_ASSERTIONS:
+		r.NoAssertions++
+	case STATUS_SKIPPED:
+		r.Skipped++
+	case STATUS_FLAKY:
+		r.Flaky++
+	case STATUS_TIMEOUT:
+		r.TimedOut++
+	}
+}
+
+// tagsSuffix returns the tags of testFunc formatted for display.
+func tagsSuffix(testFunc *TestFunc) string {
+	if len(testFunc.Tags) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(testFunc.Tags, ", ") + "]"
+}
+
+func printRunInfo(report *FinalReport) {
+	if report.SkippedByFocus > 0 {
+		fmt.Println(yellow(fmt.Sprintf("%d test(s) skipped due to focus", report.SkippedByFocus)))
+	}
+	if report.Shuffled {
+		fmt.Printf("Shuffled with seed %d (rerun with -pt.shuffle=%d)\n", report.Seed, report.Seed)
+	}
+	if report.Interrupted {
+		fmt.Println(red("Interrupted"))
+	}
+}
+
+// runsSuffix describes how many times testFunc was run or retried.
+func runsSuffix(testFunc *TestFunc) (suffix string) {
+	if testFunc.Runs > 1 {
+		suffix += fmt.Sprintf(", %d/%d runs failed", testFunc.Failures, testFunc.Runs)
+	}
+	if testFunc.Retries > 0 {
+		suffix += fmt.Sprintf(", retried %d time(s)", testFunc.Retries)
+	}
+	return suffix
+}
+
+// printLogs prints the log of testFunc if it failed or if -pt.v is
+// given.
+func printLogs(testFunc *TestFunc, indent string) {
+	switch testFunc.Status {
+	case STATUS_FAIL, STATUS_FLAKY, STATUS_TIMEOUT:
+	default:
+		if !*verbose {
+			return
+		}
+	}
+	for _, line := range testFunc.Logs {
+		fmt.Printf("%s%s\n", indent, line)
+	}
+}
+
+// formatError formats the error message of assertion, preceded by
+// its location if known.
+func formatError(assertion *Assertion) string {
+	if assertion.Filename == "" {
+		return assertion.ErrorMessage
+	}
+	return fmt.Sprintf("(%s:%d) %s", filepath.Base(assertion.Filename), assertion.Line, assertion.ErrorMessage)
 }
 
 // Formatter is the interface each formatter should implement.
@@ -61,6 +157,12 @@ Legend:
 * NA - Not Assertions found
 
 * PE - Pending test
+
+* SK - Skipped test
+
+* FL - Flaky test, it both passed and failed when run repeatedly
+
+* TO - Test timed out
 */
 type TDDFormatter struct{}
 
@@ -70,19 +172,31 @@ func (formatter *TDDFormatter) PrintSuiteInfo(suite *Suite) {
 
 func (formatter *TDDFormatter) PrintStatus(testFunc *TestFunc) {
 	callerName := testFunc.Name
+	detail := fmt.Sprintf("%d assertion(s)", len(testFunc.Assertions))
+	var label string
 	switch testFunc.Status {
 	case STATUS_FAIL:
-		fmt.Printf(formatTag+"%-30s(%d assertion(s))\n", labelFAIL, callerName, len(testFunc.Assertions))
+		label = labelFAIL
 	case STATUS_MUST_FAIL:
-		fmt.Printf(formatTag+"%-30s(%d assertion(s))\n", labelMUSTFAIL, callerName, len(testFunc.Assertions))
+		label = labelMUSTFAIL
 	case STATUS_PASS:
-		fmt.Printf(formatTag+"%-30s(%d assertion(s))\n", labelPASS, callerName, len(testFunc.Assertions))
+		label = labelPASS
 	case STATUS_PENDING:
-		fmt.Printf(formatTag+"%-30s(%d assertion(s))\n", labelPENDING, callerName, len(testFunc.Assertions))
+		label = labelPENDING
 	case STATUS_NO_ASSERTIONS:
-		fmt.Printf(formatTag+"%-30s(%d assertion(s))\n", labelNOASSERTIONS, callerName, len(testFunc.Assertions))
-
+		label = labelNOASSERTIONS
+	case STATUS_SKIPPED:
+		label, detail = labelSKIPPED, testFunc.SkipReason
+	case STATUS_FLAKY:
+		label = labelFLAKY
+	case STATUS_TIMEOUT:
+		label = labelTIMEOUT
+	default:
+		return
 	}
+	detail += runsSuffix(testFunc)
+	fmt.Printf(formatTag+"%-29s (%s)%s\n", label, callerName, detail, tagsSuffix(testFunc))
+	printLogs(testFunc, "\t\t")
 }
 
 func (formatter *TDDFormatter) PrintErrorLog(logs []*Error) {
@@ -92,16 +206,16 @@ func (formatter *TDDFormatter) PrintErrorLog(logs []*Error) {
 			if currentTestFuncHeader != error.TestFunc.Name {
 				fmt.Printf("\n%s:\n", error.TestFunc.Name)
 			}
-			filename := filepath.Base(error.Assertion.Filename)
-			fmt.Printf("\t(%s:%d) %s\n", filename, error.Assertion.Line, error.Assertion.ErrorMessage)
+			fmt.Printf("\t%s\n", formatError(error.Assertion))
 			currentTestFuncHeader = error.TestFunc.Name
 		}
 	}
 }
 
 func (formatter *TDDFormatter) PrintFinalReport(report *FinalReport) {
-	fmt.Printf("\n%d tests, %d passed, %d failed, %d expected failures, %d pending, %d with no assertions\n",
-		report.Total(), report.Passed, report.Failed, report.ExpectedFailures, report.Pending, report.NoAssertions)
+	fmt.Printf("\n%d tests, %d passed, %d failed, %d expected failures, %d pending, %d skipped, %d flaky, %d timed out, %d with no assertions\n",
+		report.Total(), report.Passed, report.Failed, report.ExpectedFailures, report.Pending, report.Skipped, report.Flaky, report.TimedOut, report.NoAssertions)
+	printRunInfo(report)
 }
 
 func (formatter *TDDFormatter) AllowedMethodsPattern() string {
@@ -111,36 +225,89 @@ func (formatter *TDDFormatter) AllowedMethodsPattern() string {
 // BDDFormatter is a formatter à la rspec.
 type BDDFormatter struct {
 	Description string
+	path        []string
 }
 
 func (formatter *BDDFormatter) PrintSuiteInfo(suite *Suite) {
 	fmt.Printf("\n%s:\n", formatter.Description)
+	formatter.path = nil
+}
+
+// printPath prints the descriptions of the groups enclosing a spec
+// that differ from those of the previous one, returning the
+// indentation of the spec.
+func (formatter *BDDFormatter) printPath(path []string) string {
+	common := 0
+	for common < len(path) && common < len(formatter.path) && path[common] == formatter.path[common] {
+		common++
+	}
+	for i := common; i < len(path); i++ {
+		fmt.Printf("%s%s\n", strings.Repeat("  ", i), path[i])
+	}
+	formatter.path = path
+	return strings.Repeat("  ", len(path))
 }
 
 func (formatter *BDDFormatter) PrintStatus(testFunc *TestFunc) {
 	shouldText := strings.Replace(testFunc.Name, "_", " ", -1)
+	if len(testFunc.Path) > 0 {
+		shouldText = strings.TrimPrefix(testFunc.Name, strings.Join(testFunc.Path, "/")+"/")
+	}
+	indent := formatter.printPath(testFunc.Path)
+	tags := tagsSuffix(testFunc)
+	if runs := runsSuffix(testFunc); runs != "" {
+		tags = "\t(" + strings.TrimPrefix(runs, ", ") + ")" + tags
+	}
 	switch testFunc.Status {
 	case STATUS_FAIL:
-		fmt.Printf("- %s\n", red(shouldText))
+		fmt.Printf("%s- %s%s\n", indent, red(shouldText), tags)
 	case STATUS_PASS:
-		fmt.Printf("- %s\n", green(shouldText))
+		fmt.Printf("%s- %s%s\n", indent, green(shouldText), tags)
 	case STATUS_MUST_FAIL:
-		fmt.Printf("- %s\n", green(shouldText))
+		fmt.Printf("%s- %s%s\n", indent, green(shouldText), tags)
 	case STATUS_PENDING:
-		fmt.Printf("- %s\t(Not Yet Implemented)\n", yellow(shouldText))
+		fmt.Printf("%s- %s\t(Not Yet Implemented)%s\n", indent, yellow(shouldText), tags)
 	case STATUS_NO_ASSERTIONS:
-		fmt.Printf("- %s\t(No assertions found)\n", yellow(shouldText))
+		fmt.Printf("%s- %s\t(No assertions found)%s\n", indent, yellow(shouldText), tags)
+	case STATUS_SKIPPED:
+		fmt.Printf("%s- %s\t(Skipped: %s)%s\n", indent, yellow(shouldText), testFunc.SkipReason, tags)
+	case STATUS_FLAKY:
+		fmt.Printf("%s- %s\t(Flaky)%s\n", indent, red(shouldText), tags)
+	case STATUS_TIMEOUT:
+		fmt.Printf("%s- %s\t(Timed out)%s\n", indent, red(shouldText), tags)
+	}
+	printSteps(testFunc, indent+"  ")
+	printLogs(testFunc, "\t"+indent)
+}
+
+// printSteps prints the steps of a scenario coloured by status.
+func printSteps(testFunc *TestFunc, indent string) {
+	for _, step := range testFunc.Steps {
+		text := step.Keyword + " " + step.Text
+		switch step.Status {
+		case STATUS_PASS:
+			text = green(text)
+		case STATUS_FAIL:
+			text = red(text)
+		default:
+			text = yellow(text)
+		}
+		fmt.Printf("%s%s\n", indent, text)
 	}
 }
 
 func (formatter *BDDFormatter) PrintFinalReport(report *FinalReport) {
-	fmt.Printf("\n%d examples, %d passed, %d failed, %d expected failures, %d pending, %d with no assertions\n",
+	fmt.Printf("\n%d examples, %d passed, %d failed, %d expected failures, %d pending, %d skipped, %d flaky, %d timed out, %d with no assertions\n",
 		report.Total(),
 		report.Passed,
 		report.Failed,
 		report.ExpectedFailures,
 		report.Pending,
+		report.Skipped,
+		report.Flaky,
+		report.TimedOut,
 		report.NoAssertions)
+	printRunInfo(report)
 }
 
 func (formatter *BDDFormatter) PrintErrorLog(logs []*Error) {
@@ -150,8 +317,7 @@ func (formatter *BDDFormatter) PrintErrorLog(logs []*Error) {
 			if currentTestFuncHeader != error.TestFunc.Name {
 				fmt.Printf("\n%s:\n", error.TestFunc.Name)
 			}
-			filename := filepath.Base(error.Assertion.Filename)
-			fmt.Printf("\t(%s:%d) %s\n", filename, error.Assertion.Line, error.Assertion.ErrorMessage)
+			fmt.Printf("\t%s\n", formatError(error.Assertion))
 			currentTestFuncHeader = error.TestFunc.Name
 		}
 	}
@@ -0,0 +1,105 @@
+package prettytest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+var testingTType = reflect.TypeOf((*testing.T)(nil))
+
+// RunFuzz uses the named method of s as the fuzz target of f. The
+// method takes the fuzzed values as arguments, e.g.
+//
+//	func (s *parserSuite) FuzzParse(input string) { ... }
+//
+// BeforeAll is called once, AfterAll when the fuzz test completes,
+// and Before/After around each input. Failed assertions are reported
+// as failures of the input. Seed corpus entries must be added with
+// f.Add before calling RunFuzz.
+func RunFuzz(f *testing.F, s tCatcher, name string) {
+	envErr := applyEnv()
+	prepare(f, s)
+	plan := newSuitePlan(s, new(TDDFormatter))
+	plan.checkOptions(envErr)
+	for _, error := range plan.errors {
+		f.Errorf("%s", formatError(error.Assertion))
+	}
+	method, ok := reflect.TypeOf(s).MethodByName(name)
+	if !ok {
+		f.Fatalf("%s has no method %s", s.suite().Name, name)
+	}
+	if method.Type.NumIn() < 2 || method.Type.NumOut() != 0 {
+		f.Fatalf("Fuzz method %s has signature %s, expected a func taking the fuzzed values", name, method.Type)
+	}
+
+	if testFunc := plan.inject(); testFunc != nil {
+		reportToT(f, testFunc)
+		f.FailNow()
+	}
+	if r := protect(func() { plan.call(plan.beforeAll, nil) }); r != nil {
+		f.Fatalf("%s", formatError(&Assertion{Filename: r.filename, Line: r.line, ErrorMessage: r.message()}))
+	}
+	f.Cleanup(func() {
+		if r := protect(func() { plan.call(plan.afterAll, nil) }); r != nil {
+			f.Errorf("%s", formatError(&Assertion{Filename: r.filename, Line: r.line, ErrorMessage: r.message()}))
+		}
+		suiteFunc := &TestFunc{Name: s.suite().Name, Status: STATUS_PASS, suite: s.suite()}
+		runCleanups(s.suite().cleanups, suiteFunc)
+		s.suite().cleanups = nil
+		reportToT(f, suiteFunc)
+	})
+
+	in := []reflect.Type{testingTType}
+	for i := 1; i < method.Type.NumIn(); i++ {
+		in = append(in, method.Type.In(i))
+	}
+	target := reflect.MakeFunc(reflect.FuncOf(in, nil, false), func(args []reflect.Value) []reflect.Value {
+		t := args[0].Interface().(*testing.T)
+		t.Helper()
+		args[0] = reflect.ValueOf(s)
+		testFunc := plan.runFuzzInput(method, args)
+		if testFunc.Status == STATUS_SKIPPED {
+			t.Skip(testFunc.SkipReason)
+		}
+		reportToT(t, testFunc)
+		return nil
+	})
+	f.Fuzz(target.Interface())
+}
+
+// runFuzzInput calls method with args, surrounded by the per-test
+// hooks and cleanups.
+func (plan *suitePlan) runFuzzInput(method reflect.Method, args []reflect.Value) *TestFunc {
+	s := plan.catcher.suite()
+	testFunc := s.startTestFunc(method.Name)
+	defer s.endTestFunc()
+	info := newTestInfo(testFunc)
+	testFunc.ctx, testFunc.cancel = context.WithCancel(context.WithValue(context.Background(), testInfoKey, info))
+
+	r := protect(func() {
+		plan.call(plan.before, info)
+		method.Func.Call(args)
+	})
+	if r != nil {
+		testFunc.failWith(r.filename, r.line, r.message())
+	}
+	testFunc.cancel()
+	if r := protect(func() { plan.call(plan.after, info) }); r != nil {
+		testFunc.failWith(r.filename, r.line, r.message())
+	}
+	runCleanups(testFunc.cleanups, testFunc)
+	return testFunc
+}
+
+// reportToT reports the failed assertions of testFunc as errors of t
+// and removes them from the error log.
+func reportToT(t testing.TB, testFunc *TestFunc) {
+	t.Helper()
+	for _, assertion := range testFunc.Assertions {
+		if !assertion.Passed {
+			t.Errorf("%s", formatError(assertion))
+		}
+	}
+	discardErrors(testFunc)
+}
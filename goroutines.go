@@ -0,0 +1,64 @@
+package prettytest
+
+import (
+	"runtime"
+	"strings"
+)
+
+// goroutineID returns the id of the calling goroutine as found in
+// the header of its stack trace.
+func goroutineID() string {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	fields := strings.Fields(string(buf))
+	if len(fields) < 2 {
+		return ""
+	}
+	return fields[1]
+}
+
+// goroutineStacks returns the stack traces of all the goroutines,
+// indexed by goroutine id.
+func goroutineStacks() map[string]string {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	stacks := make(map[string]string)
+	for _, stack := range strings.Split(string(buf), "\n\n") {
+		fields := strings.Fields(stack)
+		if len(fields) > 1 && fields[0] == "goroutine" {
+			stacks[fields[1]] = stack
+		}
+	}
+	return stacks
+}
+
+// creatorID returns the id of the goroutine which created the calling
+// one, as found at the end of its stack trace, or "" if unknown.
+func creatorID() string {
+	buf := make([]byte, 1<<12)
+	for {
+		n := runtime.Stack(buf, false)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	stack := strings.TrimSpace(string(buf))
+	i := strings.LastIndex(stack, " in goroutine ")
+	if i < 0 || strings.LastIndex(stack, "created by ") > i {
+		return ""
+	}
+	fields := strings.Fields(stack[i+len(" in goroutine "):])
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
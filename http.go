@@ -0,0 +1,26 @@
+package prettytest
+
+import (
+	"net/http"
+
+	"github.com/remogatto/prettytest/httpexpect"
+)
+
+// httpReporter records the steps of httpexpect as assertions of the
+// suite.
+type httpReporter struct {
+	suite *Suite
+}
+
+func (r httpReporter) Report(passed bool, filename string, line int, message string) {
+	r.suite.Record(passed, filename, line, message)
+}
+
+// HTTP returns a client sending requests to handler. Each step of
+// the returned chain records an assertion of the current test
+// function:
+//
+//	s.HTTP(handler).GET("/x").Expect().Status(200).JSON().Path("a.b").Equal(3)
+func (s *Suite) HTTP(handler http.Handler) *httpexpect.Client {
+	return httpexpect.New(httpReporter{s}, handler)
+}
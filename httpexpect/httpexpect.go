@@ -0,0 +1,294 @@
+// Package httpexpect provides fluent assertions for HTTP handlers,
+// built on net/http/httptest. Every step records an assertion at the
+// line that calls it:
+//
+//	func (t *testSuite) TestGetUser() {
+//		t.HTTP(handler).GET("/users/1").Expect().
+//			Status(http.StatusOK).
+//			JSON().Path("address.city").Equal("Rome")
+//	}
+//
+// Once a step fails, the following steps of the chain are skipped, so
+// only the first failure is reported.
+package httpexpect
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Reporter records the outcome of each step. Suites are adapted to
+// it by prettytest.Suite.HTTP.
+type Reporter interface {
+	Report(passed bool, filename string, line int, message string)
+}
+
+// record reports the outcome of the step calling it at the location
+// of the caller of the step.
+func record(r Reporter, passed bool, message string) bool {
+	_, filename, line, _ := runtime.Caller(2)
+	r.Report(passed, filename, line, message)
+	return passed
+}
+
+// Client sends requests to a handler.
+type Client struct {
+	reporter Reporter
+	handler  http.Handler
+}
+
+// New returns a client sending requests to handler and recording the
+// assertions with reporter.
+func New(reporter Reporter, handler http.Handler) *Client {
+	return &Client{reporter, handler}
+}
+
+// Request returns a request with the given method and path.
+func (c *Client) Request(method, path string) *Request {
+	return &Request{client: c, method: method, path: path, header: make(http.Header), query: make(url.Values)}
+}
+
+func (c *Client) GET(path string) *Request    { return c.Request(http.MethodGet, path) }
+func (c *Client) HEAD(path string) *Request   { return c.Request(http.MethodHead, path) }
+func (c *Client) POST(path string) *Request   { return c.Request(http.MethodPost, path) }
+func (c *Client) PUT(path string) *Request    { return c.Request(http.MethodPut, path) }
+func (c *Client) PATCH(path string) *Request  { return c.Request(http.MethodPatch, path) }
+func (c *Client) DELETE(path string) *Request { return c.Request(http.MethodDelete, path) }
+
+// Request is a request being built.
+type Request struct {
+	client       *Client
+	method, path string
+	header       http.Header
+	query        url.Values
+	body         []byte
+	err          error
+}
+
+// WithHeader sets a header of the request.
+func (r *Request) WithHeader(name, value string) *Request {
+	r.header.Set(name, value)
+	return r
+}
+
+// WithQuery adds a query parameter to the request.
+func (r *Request) WithQuery(name string, value interface{}) *Request {
+	r.query.Add(name, fmt.Sprint(value))
+	return r
+}
+
+// WithBody sets the body of the request.
+func (r *Request) WithBody(body string) *Request {
+	r.body = []byte(body)
+	return r
+}
+
+// WithJSON sets the body of the request to the JSON encoding of v.
+func (r *Request) WithJSON(v interface{}) *Request {
+	r.body, r.err = json.Marshal(v)
+	r.header.Set("Content-Type", "application/json")
+	return r
+}
+
+// Expect sends the request and returns the response. It fails if the
+// request can't be built or the handler panics.
+func (r *Request) Expect() *Response {
+	response := &Response{reporter: r.client.reporter}
+	target := r.path
+	if len(r.query) > 0 {
+		target += "?" + r.query.Encode()
+	}
+	if r.err != nil {
+		response.failed = !record(r.client.reporter, false, fmt.Sprintf("Cannot build request %s %s: %s", r.method, target, r.err))
+		return response
+	}
+
+	req := httptest.NewRequest(r.method, target, bytes.NewReader(r.body))
+	for name, values := range r.header {
+		req.Header[name] = values
+	}
+	recorder := httptest.NewRecorder()
+	if value := serve(r.client.handler, recorder, req); value != nil {
+		response.failed = !record(r.client.reporter, false, fmt.Sprintf("Handler panicked serving %s %s: %v", r.method, target, value))
+		return response
+	}
+	response.Raw = recorder.Result()
+	response.body = recorder.Body.Bytes()
+	record(r.client.reporter, true, fmt.Sprintf("%s %s", r.method, target))
+	return response
+}
+
+// serve calls handler recovering from panics.
+func serve(handler http.Handler, w http.ResponseWriter, req *http.Request) (panicked interface{}) {
+	defer func() { panicked = recover() }()
+	handler.ServeHTTP(w, req)
+	return nil
+}
+
+// Response is the response of a handler.
+type Response struct {
+	// Raw is the response, nil if the request failed.
+	Raw *http.Response
+
+	reporter Reporter
+	body     []byte
+	failed   bool
+}
+
+// Status asserts that the status code of the response is code.
+func (r *Response) Status(code int) *Response {
+	if !r.failed {
+		r.failed = !record(r.reporter, r.Raw.StatusCode == code, fmt.Sprintf("Expected status %d but got %d", code, r.Raw.StatusCode))
+	}
+	return r
+}
+
+// Header returns the value of a header of the response.
+func (r *Response) Header(name string) *Value {
+	if r.failed {
+		return &Value{reporter: r.reporter, failed: true}
+	}
+	return &Value{reporter: r.reporter, path: name, value: r.Raw.Header.Get(name)}
+}
+
+// Body returns the body of the response as a string.
+func (r *Response) Body() *Value {
+	if r.failed {
+		return &Value{reporter: r.reporter, failed: true}
+	}
+	return &Value{reporter: r.reporter, path: "body", value: string(r.body)}
+}
+
+// JSON asserts that the body of the response is valid JSON and
+// returns its decoded value.
+func (r *Response) JSON() *Value {
+	value := &Value{reporter: r.reporter, failed: r.failed}
+	if !r.failed {
+		err := json.Unmarshal(r.body, &value.value)
+		message := fmt.Sprintf("Expected a JSON body but got %q", r.body)
+		if err != nil {
+			message += ": " + err.Error()
+		}
+		value.failed = !record(r.reporter, err == nil, message)
+	}
+	return value
+}
+
+// Value is a value taken from a response. JSON numbers are decoded
+// as float64, but Equal compares them with any numeric type.
+type Value struct {
+	reporter Reporter
+	path     string
+	value    interface{}
+	failed   bool
+}
+
+// Raw returns the value, nil after a failed step.
+func (v *Value) Raw() interface{} {
+	return v.value
+}
+
+// Path asserts that the value contains path, a dot-separated list of
+// object keys and array indexes (e.g. "users.0.name"), and returns
+// the value found there.
+func (v *Value) Path(path string) *Value {
+	result := &Value{reporter: v.reporter, path: path, failed: v.failed}
+	if v.failed {
+		return result
+	}
+	current, found := v.value, true
+	for _, key := range strings.Split(path, ".") {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			current, found = node[key]
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			found = err == nil && i >= 0 && i < len(node)
+			if found {
+				current = node[i]
+			}
+		default:
+			found = false
+		}
+		if !found {
+			break
+		}
+	}
+	result.value = current
+	result.failed = !record(v.reporter, found, fmt.Sprintf("Path %s not found in %v", path, v.value))
+	return result
+}
+
+// Equal asserts that the value equals expected.
+func (v *Value) Equal(expected interface{}) *Value {
+	if !v.failed {
+		v.failed = !record(v.reporter, equal(expected, v.value), fmt.Sprintf("Expected %s to be equal to %v but got %v", v.name(), expected, v.value))
+	}
+	return v
+}
+
+// NotEqual asserts that the value doesn't equal expected.
+func (v *Value) NotEqual(expected interface{}) *Value {
+	if !v.failed {
+		v.failed = !record(v.reporter, !equal(expected, v.value), fmt.Sprintf("Expected %s not to be equal to %v", v.name(), expected))
+	}
+	return v
+}
+
+// Null asserts that the value is null.
+func (v *Value) Null() *Value {
+	if !v.failed {
+		v.failed = !record(v.reporter, v.value == nil, fmt.Sprintf("Expected %s to be null but got %v", v.name(), v.value))
+	}
+	return v
+}
+
+// Length asserts that the value is a string, an array or an object
+// of length n.
+func (v *Value) Length(n int) *Value {
+	if !v.failed {
+		length := -1
+		switch value := v.value.(type) {
+		case string:
+			length = len(value)
+		case []interface{}:
+			length = len(value)
+		case map[string]interface{}:
+			length = len(value)
+		}
+		v.failed = !record(v.reporter, length == n, fmt.Sprintf("Expected %s to have length %d but got %v", v.name(), n, v.value))
+	}
+	return v
+}
+
+func (v *Value) name() string {
+	if v.path == "" {
+		return "value"
+	}
+	return v.path
+}
+
+// equal compares expected with a decoded JSON value, converting
+// expected to its JSON representation first.
+func equal(expected, actual interface{}) bool {
+	if reflect.DeepEqual(expected, actual) {
+		return true
+	}
+	data, err := json.Marshal(expected)
+	if err != nil {
+		return false
+	}
+	var normalized interface{}
+	if err := json.Unmarshal(data, &normalized); err != nil {
+		return false
+	}
+	return reflect.DeepEqual(normalized, actual)
+}
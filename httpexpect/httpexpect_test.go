@@ -0,0 +1,94 @@
+package httpexpect
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type report struct {
+	passed   bool
+	location string
+	message  string
+}
+
+type fakeReporter struct {
+	reports []report
+}
+
+func (r *fakeReporter) Report(passed bool, filename string, line int, message string) {
+	r.reports = append(r.reports, report{passed, filepath.Base(filename), message})
+}
+
+var handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/user":
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"name":    r.URL.Query().Get("name"),
+			"address": map[string]interface{}{"number": 3},
+			"tags":    []string{"a", "b"},
+		})
+	case "/echo":
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(r.Header.Get("Content-Type")))
+	case "/panic":
+		panic("boom")
+	default:
+		http.NotFound(w, r)
+	}
+})
+
+func TestChain(t *testing.T) {
+	r := new(fakeReporter)
+	client := New(r, handler)
+	user := client.GET("/user").WithQuery("name", "andrea").Expect().Status(http.StatusOK).JSON()
+	user.Path("address.number").Equal(3)
+	user.Path("tags.1").Equal("b")
+	user.Path("name").Equal("andrea")
+	user.Path("tags").Length(2)
+	client.POST("/echo").WithJSON(42).Expect().Status(http.StatusCreated).Body().Equal("application/json")
+	for _, report := range r.reports {
+		if !report.passed {
+			t.Errorf("Unexpected failure: %s", report.message)
+		}
+		if report.location != "httpexpect_test.go" {
+			t.Errorf("Steps should be recorded at the caller's line but %q was recorded in %s", report.message, report.location)
+		}
+	}
+	if len(r.reports) != 14 {
+		t.Errorf("Expected 14 steps to be recorded but got %d", len(r.reports))
+	}
+}
+
+func TestFailures(t *testing.T) {
+	expected := map[string]func(c *Client){
+		"Expected status 200 but got 404": func(c *Client) {
+			c.GET("/missing").Expect().Status(http.StatusOK).JSON().Path("a").Equal(1)
+		},
+		"Path address.street not found": func(c *Client) {
+			c.GET("/user").Expect().JSON().Path("address.street").Equal("Main")
+		},
+		"Expected address.number to be equal to 4 but got 3": func(c *Client) {
+			c.GET("/user").Expect().JSON().Path("address.number").Equal(4)
+		},
+		"Handler panicked serving GET /panic: boom": func(c *Client) {
+			c.GET("/panic").Expect().Status(http.StatusOK)
+		},
+	}
+	for message, chain := range expected {
+		r := new(fakeReporter)
+		chain(New(r, handler))
+		var failures []report
+		for _, report := range r.reports {
+			if !report.passed {
+				failures = append(failures, report)
+			}
+		}
+		if len(failures) != 1 || !strings.HasPrefix(failures[0].message, message) {
+			t.Errorf("Expected a single failure %q but got %v", message, failures)
+		}
+	}
+}
@@ -2,16 +2,8 @@
 
 package prettytest
 
-import (
-	"flag"
-	"regexp"
-)
+import "flag"
 
-var (
-	testToRun = flag.String("pt.run", "", "[prettytest] regular expression that filters tests and examples to run")
-)
-
-func filterMethod(name string) bool {
-	ok, _ := regexp.MatchString(*testToRun, name)
-	return ok
-}
+// flags holds the prettytest options, which are given as test flags
+// like -pt.run or through the environment (see applyEnv).
+var flags = flag.CommandLine
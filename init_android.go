@@ -2,6 +2,9 @@
 
 package prettytest
 
-func filterMethod(name string) bool {
-	return true
-}
+import "flag"
+
+// flags holds the prettytest options. Android test binaries can't be
+// given test flags, so they are only set through the environment (see
+// applyEnv).
+var flags = flag.NewFlagSet("prettytest", flag.ContinueOnError)
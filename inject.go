@@ -0,0 +1,218 @@
+package prettytest
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// Values of the pt struct tag recognized on suite fields.
+const (
+	injectTempDir    = "tempdir"
+	injectHTTPServer = "httpserver"
+	injectFixture    = "fixture"
+	injectProvider   = "provider"
+)
+
+var (
+	providersMu sync.Mutex
+	providers   = make(map[reflect.Type]reflect.Value)
+
+	errorType      = reflect.TypeOf((*error)(nil)).Elem()
+	teardownType   = reflect.TypeOf(func() {})
+	httpServerType = reflect.TypeOf((*httptest.Server)(nil))
+)
+
+// RegisterProvider registers fn as the provider of the values of
+// its first result type. Exported suite fields of that type are set
+// by calling fn before BeforeAll, unless tagged with pt:"-". fn
+// takes no arguments and returns the value, optionally followed by
+// a teardown function called after AfterAll and by an error:
+//
+//	prettytest.RegisterProvider(func() (*sql.DB, func(), error) {
+//		db, err := sql.Open("sqlite3", ":memory:")
+//		return db, func() { db.Close() }, err
+//	})
+//
+// RegisterProvider panics if fn doesn't have one of these
+// signatures.
+func RegisterProvider(fn interface{}) {
+	f := reflect.ValueOf(fn)
+	t := f.Type()
+	valid := f.Kind() == reflect.Func && t.NumIn() == 0 && t.NumOut() >= 1 && t.NumOut() <= 3
+	for i := 1; valid && i < t.NumOut(); i++ {
+		switch {
+		case t.Out(i) == teardownType && i == 1:
+		case t.Out(i) == errorType && i == t.NumOut()-1:
+		default:
+			valid = false
+		}
+	}
+	if !valid {
+		panic(fmt.Sprintf("RegisterProvider expects a func() (T[, func()][, error]), got %T", fn))
+	}
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[t.Out(0)] = f
+}
+
+func lookupProvider(t reflect.Type) (reflect.Value, bool) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	provider, ok := providers[t]
+	return provider, ok
+}
+
+// injection describes how a suite field is populated.
+type injection struct {
+	field   reflect.StructField
+	kind    string
+	arg     string
+	handler reflect.Value
+}
+
+// collectInjections finds the suite fields to populate, recording
+// misconfigured ones as plan errors.
+func (plan *suitePlan) collectInjections() {
+	v := reflect.ValueOf(plan.catcher)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return
+	}
+	t := v.Elem().Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, tagged := field.Tag.Lookup("pt")
+		if tag == "-" || field.Anonymous {
+			continue
+		}
+		if !tagged {
+			if _, ok := lookupProvider(field.Type); ok && field.PkgPath == "" {
+				plan.injections = append(plan.injections, &injection{field: field, kind: injectProvider})
+			}
+			continue
+		}
+		kind, arg := tag, ""
+		if i := strings.Index(tag, "="); i >= 0 {
+			kind, arg = tag[:i], tag[i+1:]
+		}
+		in := &injection{field: field, kind: kind, arg: arg}
+		if err := plan.validateInjection(in); err != nil {
+			plan.addError(field.Name, "", 0, fmt.Sprintf("Field %s tagged pt:%q %s", field.Name, tag, err))
+			continue
+		}
+		plan.injections = append(plan.injections, in)
+	}
+}
+
+func (plan *suitePlan) validateInjection(in *injection) error {
+	if in.field.PkgPath != "" {
+		return fmt.Errorf("must be exported")
+	}
+	switch in.kind {
+	case injectTempDir:
+		if in.field.Type.Kind() != reflect.String {
+			return fmt.Errorf("must be a string")
+		}
+	case injectFixture:
+		if in.arg == "" {
+			return fmt.Errorf("must name the fixture, e.g. pt:\"fixture=users.json\"")
+		}
+	case injectHTTPServer:
+		if in.field.Type != httpServerType {
+			return fmt.Errorf("must be a *httptest.Server")
+		}
+		if in.arg == "" {
+			handler, ok := plan.catcher.(http.Handler)
+			if !ok {
+				return fmt.Errorf("needs a handler method, e.g. pt:\"httpserver=ServeAPI\", or the suite to be an http.Handler")
+			}
+			in.handler = reflect.ValueOf(handler)
+			return nil
+		}
+		method := reflect.ValueOf(plan.catcher).MethodByName(in.arg)
+		if !method.IsValid() {
+			return fmt.Errorf("refers to the missing method %s", in.arg)
+		}
+		switch method.Interface().(type) {
+		case func(http.ResponseWriter, *http.Request), func() http.Handler:
+			in.handler = method
+		default:
+			return fmt.Errorf("refers to method %s with signature %s, expected func(http.ResponseWriter, *http.Request) or func() http.Handler", in.arg, method.Type())
+		}
+	case injectProvider:
+		if _, ok := lookupProvider(in.field.Type); !ok {
+			return fmt.Errorf("has no provider registered for %s", in.field.Type)
+		}
+	default:
+		return fmt.Errorf("has an unknown kind %q", in.kind)
+	}
+	return nil
+}
+
+// inject populates the suite fields, registering their teardown as
+// suite cleanups. It returns a failed test function named after the
+// field that can't be populated, if any.
+func (plan *suitePlan) inject() *TestFunc {
+	s := plan.catcher.suite()
+	v := reflect.ValueOf(plan.catcher).Elem()
+	for _, in := range plan.injections {
+		field := v.FieldByIndex(in.field.Index)
+		if err := plan.populate(s, in, field); err != nil {
+			testFunc := &TestFunc{Name: in.field.Name, Status: STATUS_FAIL, suite: s}
+			testFunc.failWith("", 0, fmt.Sprintf("Cannot populate field %s: %s", in.field.Name, err))
+			return testFunc
+		}
+	}
+	return nil
+}
+
+func (plan *suitePlan) populate(s *Suite, in *injection, field reflect.Value) (err error) {
+	if r := protect(func() { err = plan.populateField(s, in, field) }); r != nil {
+		return fmt.Errorf("%s", r.message())
+	}
+	return err
+}
+
+func (plan *suitePlan) populateField(s *Suite, in *injection, field reflect.Value) error {
+	switch in.kind {
+	case injectTempDir:
+		field.SetString(s.TempDir())
+	case injectHTTPServer:
+		var handler http.Handler
+		switch fn := in.handler.Interface().(type) {
+		case func(http.ResponseWriter, *http.Request):
+			handler = http.HandlerFunc(fn)
+		case func() http.Handler:
+			handler = fn()
+		case http.Handler:
+			handler = fn
+		}
+		server := httptest.NewServer(handler)
+		s.Cleanup(server.Close)
+		field.Set(reflect.ValueOf(server))
+	case injectFixture:
+		ptr := reflect.New(field.Type())
+		if err := loadFixture(s, in.arg, ptr.Interface(), nil); err != nil {
+			return err
+		}
+		field.Set(ptr.Elem())
+	case injectProvider:
+		provider, _ := lookupProvider(field.Type())
+		out := provider.Call(nil)
+		for _, result := range out[1:] {
+			switch fn := result.Interface().(type) {
+			case func():
+				if fn != nil {
+					s.Cleanup(fn)
+				}
+			case error:
+				return fn
+			}
+		}
+		field.Set(out[0])
+	}
+	return nil
+}
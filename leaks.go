@@ -0,0 +1,89 @@
+package prettytest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Goroutine leak checking modes (see -pt.leaks).
+const (
+	leaksOff   = "off"
+	leaksTest  = "test"
+	leaksSuite = "suite"
+)
+
+// LeakChecked is implemented by suites that want goroutine leaks to
+// be checked regardless of -pt.leaks. LeakCheck returns "test" to
+// check each test method, from Before to the cleanups, or "suite" to
+// check the suite as a whole, from BeforeAll to the suite cleanups.
+type LeakChecked interface {
+	LeakCheck() string
+}
+
+// systemGoroutines are started lazily by the runtime or by the
+// runner itself and are never reported as leaks.
+var systemGoroutines = []string{
+	"os/signal.loop",
+	"os/signal.signal_recv",
+	"runtime.ensureSigM",
+	"testing.(*T).Run",
+}
+
+func (plan *suitePlan) leakMode() string {
+	mode := *leaks
+	if checked, ok := plan.catcher.(LeakChecked); ok {
+		mode = checked.LeakCheck()
+	}
+	if mode == "" {
+		return leaksOff
+	}
+	return mode
+}
+
+// goroutineSnapshot returns the ids of the running goroutines.
+func goroutineSnapshot() map[string]bool {
+	snapshot := make(map[string]bool)
+	for id := range goroutineStacks() {
+		snapshot[id] = true
+	}
+	return snapshot
+}
+
+// leakedGoroutines returns the stacks of the goroutines started
+// after snapshot was taken and still running after the grace period
+// given by -pt.leak-grace.
+func leakedGoroutines(snapshot map[string]bool) []string {
+	deadline := time.Now().Add(*leakGrace)
+	for {
+		var leaked []string
+		for id, stack := range goroutineStacks() {
+			if !snapshot[id] && !isSystemGoroutine(stack) {
+				leaked = append(leaked, stack)
+			}
+		}
+		if len(leaked) == 0 || time.Now().After(deadline) {
+			sort.Strings(leaked)
+			return leaked
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func isSystemGoroutine(stack string) bool {
+	for _, name := range systemGoroutines {
+		if strings.Contains(stack, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkLeaks fails testFunc if goroutines started after snapshot are
+// still running.
+func checkLeaks(testFunc *TestFunc, snapshot map[string]bool, filename string, line int) {
+	if leaked := leakedGoroutines(snapshot); len(leaked) > 0 {
+		testFunc.failWith(filename, line, fmt.Sprintf("%d goroutine(s) leaked:\n%s", len(leaked), strings.Join(leaked, "\n\n")))
+	}
+}
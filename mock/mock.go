@@ -0,0 +1,356 @@
+// Package mock provides a Mock type to embed in hand-written fakes.
+// Expectations are declared with On and checked with
+// AssertExpectations, which records a prettytest assertion for each of
+// them:
+//
+//	type fakeStore struct {
+//		mock.Mock
+//	}
+//
+//	func (f *fakeStore) Get(key string) (string, error) {
+//		args := f.Called(key)
+//		return args.String(0), args.Error(1)
+//	}
+//
+//	func (t *testSuite) TestCache() {
+//		store := new(fakeStore)
+//		store.On("Get", "answer").Return("42", nil).Times(1)
+//		cache := NewCache(store)
+//		cache.Get("answer")
+//		cache.Get("answer")
+//		store.AssertExpectations(t)
+//	}
+package mock
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/remogatto/prettytest"
+)
+
+// Recorder records the outcome of the checks made by a Mock.
+// *prettytest.Suite, and thus every suite, implements it.
+type Recorder interface {
+	Record(passed bool, filename string, line int, message string) *prettytest.Assertion
+}
+
+// Matcher matches the arguments of a call.
+type Matcher interface {
+	Match(value interface{}) bool
+	String() string
+}
+
+type matcher struct {
+	match       func(value interface{}) bool
+	description string
+}
+
+func (m *matcher) Match(value interface{}) bool { return m.match(value) }
+func (m *matcher) String() string               { return m.description }
+
+// Anything matches any argument.
+var Anything Matcher = &matcher{func(interface{}) bool { return true }, "Anything"}
+
+// AnythingOfType matches the arguments whose type, as printed by
+// %T, is typeName (e.g. "string" or "*http.Request").
+func AnythingOfType(typeName string) Matcher {
+	return &matcher{
+		func(value interface{}) bool { return fmt.Sprintf("%T", value) == typeName },
+		fmt.Sprintf("AnythingOfType(%s)", typeName),
+	}
+}
+
+// MatchedBy matches the arguments for which fn, a func taking a
+// single argument and returning bool, returns true. Arguments not
+// assignable to the argument of fn don't match.
+func MatchedBy(fn interface{}) Matcher {
+	f := reflect.ValueOf(fn)
+	if f.Kind() != reflect.Func || f.Type().NumIn() != 1 || f.Type().NumOut() != 1 || f.Type().Out(0).Kind() != reflect.Bool {
+		panic(fmt.Sprintf("MatchedBy expects a func(T) bool, got %T", fn))
+	}
+	in := f.Type().In(0)
+	return &matcher{
+		func(value interface{}) bool {
+			arg := reflect.ValueOf(value)
+			if !arg.IsValid() {
+				switch in.Kind() {
+				case reflect.Chan, reflect.Func, reflect.Interface, reflect.Map, reflect.Ptr, reflect.Slice:
+					arg = reflect.Zero(in)
+				default:
+					return false
+				}
+			}
+			if !arg.Type().AssignableTo(in) {
+				return false
+			}
+			return f.Call([]reflect.Value{arg})[0].Bool()
+		},
+		fmt.Sprintf("MatchedBy(%s)", f.Type()),
+	}
+}
+
+// Arguments are the arguments of a call or the values it returns.
+// The getters return the zero value when an argument is missing or
+// has a different type.
+type Arguments []interface{}
+
+// Get returns the i-th argument, or nil.
+func (args Arguments) Get(i int) interface{} {
+	if i < 0 || i >= len(args) {
+		return nil
+	}
+	return args[i]
+}
+
+// String returns the i-th argument as a string.
+func (args Arguments) String(i int) string {
+	s, _ := args.Get(i).(string)
+	return s
+}
+
+// Int returns the i-th argument as an int.
+func (args Arguments) Int(i int) int {
+	n, _ := args.Get(i).(int)
+	return n
+}
+
+// Bool returns the i-th argument as a bool.
+func (args Arguments) Bool(i int) bool {
+	b, _ := args.Get(i).(bool)
+	return b
+}
+
+// Error returns the i-th argument as an error.
+func (args Arguments) Error(i int) error {
+	err, _ := args.Get(i).(error)
+	return err
+}
+
+func (args Arguments) match(values []interface{}) bool {
+	if len(args) != len(values) {
+		return false
+	}
+	for i, expected := range args {
+		if m, ok := expected.(Matcher); ok {
+			if !m.Match(values[i]) {
+				return false
+			}
+		} else if !reflect.DeepEqual(expected, values[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func (args Arguments) format() string {
+	result := make([]string, len(args))
+	for i, arg := range args {
+		if m, ok := arg.(Matcher); ok {
+			result[i] = m.String()
+		} else {
+			result[i] = fmt.Sprintf("%#v", arg)
+		}
+	}
+	return strings.Join(result, ", ")
+}
+
+// atLeastOnce is the default number of times of a Call, which is
+// expected to be called at least once.
+const atLeastOnce = -1
+
+// Call is an expected call declared with On.
+type Call struct {
+	Method          string
+	Arguments       Arguments
+	ReturnArguments Arguments
+
+	times    int
+	calls    int
+	run      func(args Arguments)
+	filename string
+	line     int
+}
+
+// Return sets the values returned by Called for this call.
+func (c *Call) Return(values ...interface{}) *Call {
+	c.ReturnArguments = values
+	return c
+}
+
+// Times sets how many times the call is expected, Times(0) meaning
+// that it must not be called. By default the call is expected at
+// least once.
+func (c *Call) Times(n int) *Call {
+	c.times = n
+	return c
+}
+
+// Once expects the call exactly once.
+func (c *Call) Once() *Call {
+	return c.Times(1)
+}
+
+// Run sets a function called with the arguments of each matching
+// call, before Called returns.
+func (c *Call) Run(fn func(args Arguments)) *Call {
+	c.run = fn
+	return c
+}
+
+func (c *Call) String() string {
+	return fmt.Sprintf("%s(%s)", c.Method, c.Arguments.format())
+}
+
+// Mock records the calls made to a fake and matches them against
+// the expected calls. It is safe for concurrent use.
+type Mock struct {
+	mu         sync.Mutex
+	expected   []*Call
+	calls      []*Call
+	unexpected []*Call
+	helpers    map[string]bool
+}
+
+// Helper marks the calling function as a helper, like
+// testing.T.Helper: it is skipped when locating expectations and
+// assertions. Mocks generated by ptmock call it in their On and
+// Assert methods.
+func (m *Mock) Helper() {
+	pc, _, _, _ := runtime.Caller(1)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.helpers == nil {
+		m.helpers = make(map[string]bool)
+	}
+	m.helpers[runtime.FuncForPC(pc).Name()] = true
+}
+
+// caller returns the location of the caller of the function calling
+// caller, skipping helpers.
+func (m *Mock) caller() (string, int) {
+	pc := make([]uintptr, 16)
+	frames := runtime.CallersFrames(pc[:runtime.Callers(3, pc)])
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for {
+		frame, more := frames.Next()
+		if !m.helpers[frame.Function] || !more {
+			return frame.File, frame.Line
+		}
+	}
+}
+
+// On declares an expected call to method with the given arguments.
+// Arguments are compared with reflect.DeepEqual, unless they are a
+// Matcher like Anything.
+func (m *Mock) On(method string, args ...interface{}) *Call {
+	filename, line := m.caller()
+	c := &Call{Method: method, Arguments: args, times: atLeastOnce, filename: filename, line: line}
+	m.mu.Lock()
+	m.expected = append(m.expected, c)
+	m.mu.Unlock()
+	return c
+}
+
+// Called records a call to the method calling it and returns the
+// values set with Return by the first matching expectation.
+// Unexpected calls return no values and are reported by
+// AssertExpectations.
+func (m *Mock) Called(args ...interface{}) Arguments {
+	pc, _, _, _ := runtime.Caller(1)
+	name := runtime.FuncForPC(pc).Name()
+	return m.methodCalled(name[strings.LastIndex(name, ".")+1:], args)
+}
+
+// MethodCalled is like Called for the given method name.
+func (m *Mock) MethodCalled(method string, args ...interface{}) Arguments {
+	return m.methodCalled(method, args)
+}
+
+func (m *Mock) methodCalled(method string, args []interface{}) Arguments {
+	_, filename, line, _ := runtime.Caller(3)
+	call := &Call{Method: method, Arguments: args, filename: filename, line: line}
+
+	m.mu.Lock()
+	m.calls = append(m.calls, call)
+	var found *Call
+	for _, c := range m.expected {
+		if c.Method == method && c.Arguments.match(args) && (c.times == atLeastOnce || c.calls < c.times) {
+			found = c
+			break
+		}
+	}
+	if found == nil {
+		m.unexpected = append(m.unexpected, call)
+		m.mu.Unlock()
+		return nil
+	}
+	found.calls++
+	m.mu.Unlock()
+
+	if found.run != nil {
+		found.run(args)
+	}
+	return found.ReturnArguments
+}
+
+// Calls returns the calls made so far.
+func (m *Mock) Calls() []*Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*Call(nil), m.calls...)
+}
+
+// AssertExpectations records an assertion for each expected call,
+// at the line where it was declared, and a failed assertion for each
+// unexpected call, at the line where it was made.
+func (m *Mock) AssertExpectations(r Recorder) []*prettytest.Assertion {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var assertions []*prettytest.Assertion
+	for _, c := range m.expected {
+		var passed bool
+		var message string
+		if c.times == atLeastOnce {
+			passed = c.calls > 0
+			message = fmt.Sprintf("Expected %s to be called but it was not", c)
+		} else {
+			passed = c.calls == c.times
+			message = fmt.Sprintf("Expected %s to be called %d time(s) but it was called %d time(s)", c, c.times, c.calls)
+		}
+		assertions = append(assertions, r.Record(passed, c.filename, c.line, message))
+	}
+	for _, c := range m.unexpected {
+		assertions = append(assertions, r.Record(false, c.filename, c.line, fmt.Sprintf("Unexpected call to %s", c)))
+	}
+	return assertions
+}
+
+// AssertCalled asserts that method was called with arguments
+// matching args.
+func (m *Mock) AssertCalled(r Recorder, method string, args ...interface{}) *prettytest.Assertion {
+	filename, line := m.caller()
+	return r.Record(m.called(method, args), filename, line, fmt.Sprintf("Expected %s(%s) to be called", method, Arguments(args).format()))
+}
+
+// AssertNotCalled asserts that method was not called with arguments
+// matching args.
+func (m *Mock) AssertNotCalled(r Recorder, method string, args ...interface{}) *prettytest.Assertion {
+	filename, line := m.caller()
+	return r.Record(!m.called(method, args), filename, line, fmt.Sprintf("Expected %s(%s) not to be called", method, Arguments(args).format()))
+}
+
+func (m *Mock) called(method string, args Arguments) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, c := range m.calls {
+		if c.Method == method && args.match(c.Arguments) {
+			return true
+		}
+	}
+	return false
+}
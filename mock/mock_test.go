@@ -0,0 +1,107 @@
+package mock
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/remogatto/prettytest"
+)
+
+type fakeStore struct {
+	Mock
+}
+
+func (f *fakeStore) Get(key string) (string, error) {
+	args := f.Called(key)
+	return args.String(0), args.Error(1)
+}
+
+func (f *fakeStore) Put(key string, value interface{}) {
+	f.Called(key, value)
+}
+
+type mockSuite struct {
+	prettytest.Suite
+}
+
+func TestRunner(t *testing.T) {
+	prettytest.Run(
+		t,
+		new(mockSuite),
+	)
+}
+
+func (t *mockSuite) TestReturn() {
+	store := new(fakeStore)
+	store.On("Get", "answer").Return("42", nil).Once()
+	store.On("Get", Anything).Return("", errors.New("not found"))
+	value, err := store.Get("answer")
+	t.Equal("42", value)
+	t.Nil(err)
+	_, err = store.Get("answer")
+	t.Equal("not found", err.Error())
+	for _, assertion := range store.AssertExpectations(t) {
+		t.True(assertion.Passed)
+	}
+}
+
+func (t *mockSuite) TestMatchers() {
+	store := new(fakeStore)
+	store.On("Put", AnythingOfType("string"), MatchedBy(func(n int) bool { return n > 0 }))
+	store.Put("a", 1)
+	store.Put("b", -1)
+	store.Put("c", "not an int")
+	t.MustFail()
+	assertions := store.AssertExpectations(t)
+	t.Equal(3, len(assertions))
+	t.True(assertions[0].Passed)
+	t.Contain("Unexpected call to Put(\"b\", -1)", assertions[1].ErrorMessage)
+	t.Contain("mock_test.go", assertions[1].Filename)
+}
+
+func (t *mockSuite) TestTimes() {
+	store := new(fakeStore)
+	store.On("Get", "key").Return("value", nil).Times(2)
+	store.Get("key")
+	t.MustFail()
+	assertions := store.AssertExpectations(t)
+	t.Equal(1, len(assertions))
+	t.True(strings.HasSuffix(assertions[0].ErrorMessage, "called 2 time(s) but it was called 1 time(s)"))
+}
+
+func (t *mockSuite) TestNever() {
+	store := new(fakeStore)
+	store.On("Get", "key").Times(0)
+	assertions := store.AssertExpectations(t)
+	t.Equal(1, len(assertions))
+	t.True(assertions[0].Passed)
+}
+
+func (t *mockSuite) TestCalledDespiteNever() {
+	store := new(fakeStore)
+	store.On("Get", "key").Times(0)
+	store.Get("key")
+	t.MustFail()
+	assertions := store.AssertExpectations(t)
+	t.Equal(2, len(assertions))
+	t.True(strings.HasPrefix(assertions[1].ErrorMessage, "Unexpected call to Get"))
+}
+
+func (t *mockSuite) TestUnexpectedCall() {
+	store := new(fakeStore)
+	value, err := store.Get("missing")
+	t.Equal("", value)
+	t.Nil(err)
+	t.MustFail()
+	t.Equal(1, len(store.AssertExpectations(t)))
+}
+
+func (t *mockSuite) TestAssertCalled() {
+	store := new(fakeStore)
+	store.On("Put", Anything, Anything)
+	store.Put("key", 42)
+	t.True(store.AssertCalled(t, "Put", "key", 42).Passed)
+	t.True(store.AssertNotCalled(t, "Put", "other", Anything).Passed)
+	t.Equal(1, len(store.Calls()))
+}
@@ -0,0 +1,438 @@
+package prettytest
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Names of the lifecycle hooks recognized by the runner. A method is
+// considered a hook only when its name matches exactly, so methods
+// like BeforeAllUsers or AfterburnerTest are left alone.
+const (
+	hookBeforeAll     = "BeforeAll"
+	hookAfterAll      = "AfterAll"
+	hookBefore        = "Before"
+	hookAfter         = "After"
+	hookSetupSuite    = "SetupSuite"
+	hookTearDownSuite = "TearDownSuite"
+	hookSetupTest     = "SetupTest"
+	hookTearDownTest  = "TearDownTest"
+	hookBeforeTest    = "BeforeTest"
+	hookAfterTest     = "AfterTest"
+)
+
+var hookNames = map[string]bool{
+	hookBeforeAll:     true,
+	hookAfterAll:      true,
+	hookBefore:        true,
+	hookAfter:         true,
+	hookSetupSuite:    true,
+	hookTearDownSuite: true,
+	hookSetupTest:     true,
+	hookTearDownTest:  true,
+	hookBeforeTest:    true,
+	hookAfterTest:     true,
+}
+
+// testHookNames are the hooks run around each test. They may be
+// declared either as func() or as func(*TestInfo).
+var testHookNames = map[string]bool{
+	hookBefore:       true,
+	hookAfter:        true,
+	hookSetupTest:    true,
+	hookTearDownTest: true,
+	hookBeforeTest:   true,
+	hookAfterTest:    true,
+}
+
+var testInfoType = reflect.TypeOf((*TestInfo)(nil))
+
+// SetupSuite is implemented by suites that want a suite-level setup
+// step. It is run once, after BeforeAll, before the first test.
+type SetupSuite interface {
+	SetupSuite()
+}
+
+// TearDownSuite is implemented by suites that want a suite-level
+// teardown step. It is run once, before AfterAll, after the last
+// test.
+type TearDownSuite interface {
+	TearDownSuite()
+}
+
+// SetupTest is implemented by suites that want a per-test setup
+// step. It is run after Before, before each test.
+type SetupTest interface {
+	SetupTest()
+}
+
+// TearDownTest is implemented by suites that want a per-test
+// teardown step. It is run before After, after each test.
+type TearDownTest interface {
+	TearDownTest()
+}
+
+// BeforeTest is implemented by suites that need to know which test
+// is about to run. It is run after Before and SetupTest.
+type BeforeTest interface {
+	BeforeTest(info *TestInfo)
+}
+
+// AfterTest is implemented by suites that need to know which test
+// has just run and how it went. It is run before TearDownTest and
+// After.
+type AfterTest interface {
+	AfterTest(info *TestInfo)
+}
+
+// testCase is a test to run: a test method of the suite or of one
+// of the shared examples it includes, or a spec declared with It.
+type testCase struct {
+	name     string
+	method   reflect.Method
+	receiver reflect.Value
+	fn       func()
+	path     []string
+	filename string
+	line     int
+	focused  bool
+}
+
+func (tc *testCase) call() {
+	if tc.fn != nil {
+		tc.fn()
+		return
+	}
+	tc.method.Func.Call([]reflect.Value{tc.receiver})
+}
+
+// location returns where the test is declared.
+func (tc *testCase) location() (string, int) {
+	if tc.fn != nil {
+		return tc.filename, tc.line
+	}
+	return methodLocation(tc.method)
+}
+
+type hook struct {
+	name     string
+	fn       reflect.Value
+	withInfo bool
+}
+
+// suitePlan is the result of the discovery phase: it holds the hooks
+// and the test methods of a suite in the order they will be run,
+// together with the misconfigurations found while building it.
+type suitePlan struct {
+	catcher             tCatcher
+	beforeAll, afterAll []*hook
+	before, after       []*hook
+	methods             []*testCase
+	benchmarks          []reflect.Method
+	specMethods         []*hook
+	tags                map[string][]string
+	timeouts            map[string]time.Duration
+	injections          []*injection
+	errors              []*Error
+}
+
+func newSuitePlan(s tCatcher, formatter Formatter) *suitePlan {
+	plan := &suitePlan{catcher: s}
+	pattern, err := regexp.Compile(formatter.AllowedMethodsPattern())
+	if err != nil {
+		plan.addError(formatter.AllowedMethodsPattern(), "", 0, fmt.Sprintf("Invalid formatter method pattern: %s", err))
+		return plan
+	}
+
+	hooks := make(map[string]*hook)
+	iType := reflect.TypeOf(s)
+	for i := 0; i < iType.NumMethod(); i++ {
+		method := iType.Method(i)
+		switch {
+		case testHookNames[method.Name] && isTestHookWithInfo(method):
+			hooks[method.Name] = &hook{method.Name, method.Func, true}
+		case testHookNames[method.Name]:
+			if plan.validate(method, "Hook", "func() or func(*TestInfo)") {
+				hooks[method.Name] = &hook{method.Name, method.Func, false}
+			}
+		case hookNames[method.Name]:
+			if plan.validate(method, "Hook", "func()") {
+				hooks[method.Name] = &hook{method.Name, method.Func, false}
+			}
+		case hasWordPrefix(method.Name, benchmarkPrefix) && takesBenchmark(method):
+			if plan.validate(method, "Benchmark method", "func(*B)", benchmarkType) {
+				plan.benchmarks = append(plan.benchmarks, method)
+			}
+		case hasWordPrefix(method.Name, specPrefix):
+			if plan.validate(method, "Spec method", "func()") {
+				plan.specMethods = append(plan.specMethods, &hook{method.Name, method.Func, false})
+			}
+		case (focusedMethod(pattern, method.Name) || pattern.MatchString(method.Name)) && filterMethod(s.suite().Name, method.Name):
+			if plan.validate(method, "Test method", "func()") {
+				plan.methods = append(plan.methods, &testCase{
+					name:     method.Name,
+					method:   method,
+					receiver: reflect.ValueOf(s),
+					focused:  focusedMethod(pattern, method.Name),
+				})
+			}
+		}
+	}
+
+	plan.collectShared(pattern)
+	plan.collectSpecs()
+	plan.collectInjections()
+	if tagged, ok := s.(Tagged); ok {
+		plan.tags = tagged.Tags()
+	}
+	if timed, ok := s.(Timed); ok {
+		plan.timeouts = timed.Timeouts()
+	}
+	switch mode := plan.leakMode(); mode {
+	case leaksOff, leaksTest, leaksSuite:
+	default:
+		plan.addError("LeakCheck", "", 0, fmt.Sprintf("Invalid goroutine leak checking mode %q, expected off, test or suite", mode))
+	}
+
+	plan.beforeAll = collectHooks(hooks, hookBeforeAll, hookSetupSuite)
+	plan.afterAll = collectHooks(hooks, hookTearDownSuite, hookAfterAll)
+	plan.before = collectHooks(hooks, hookBefore, hookSetupTest, hookBeforeTest)
+	plan.after = collectHooks(hooks, hookAfterTest, hookTearDownTest, hookAfter)
+	return plan
+}
+
+func collectHooks(hooks map[string]*hook, names ...string) (result []*hook) {
+	for _, name := range names {
+		if h, ok := hooks[name]; ok {
+			result = append(result, h)
+		}
+	}
+	return result
+}
+
+// hasWordPrefix checks if name starts with prefix as a whole word,
+// i.e. followed by an upper-case letter, an underscore or nothing:
+// BenchmarkParse has the Benchmark prefix, Benchmarked doesn't.
+func hasWordPrefix(name, prefix string) bool {
+	if !strings.HasPrefix(name, prefix) {
+		return false
+	}
+	rest := name[len(prefix):]
+	if rest == "" {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(rest)
+	return r == '_' || unicode.IsUpper(r)
+}
+
+func isTestHookWithInfo(method reflect.Method) bool {
+	return method.Type.NumIn() == 2 && method.Type.In(1) == testInfoType && method.Type.NumOut() == 0
+}
+
+// validate checks that method takes the given arguments, if any,
+// and returns no values. Otherwise it records a misconfiguration
+// error mentioning the expected signature.
+func (plan *suitePlan) validate(method reflect.Method, kind, expected string, args ...reflect.Type) bool {
+	if method.Type.NumIn() == len(args)+1 && method.Type.NumOut() == 0 {
+		valid := true
+		for i, arg := range args {
+			valid = valid && method.Type.In(i+1) == arg
+		}
+		if valid {
+			return true
+		}
+	}
+	filename, line := methodLocation(method)
+	plan.addError(method.Name, filename, line, fmt.Sprintf("%s %s has signature %s, expected %s", kind, method.Name, method.Type, expected))
+	return false
+}
+
+func (plan *suitePlan) addError(name, filename string, line int, message string) {
+	testFunc := &TestFunc{Name: name, Status: STATUS_FAIL, suite: plan.catcher.suite()}
+	assertion := &Assertion{
+		Name:         "plan",
+		Filename:     filename,
+		Line:         line,
+		ErrorMessage: message,
+		suite:        testFunc.suite,
+		testFunc:     testFunc,
+	}
+	testFunc.appendAssertion(assertion)
+	plan.errors = append(plan.errors, &Error{testFunc.suite, testFunc, assertion})
+}
+
+func methodLocation(method reflect.Method) (string, int) {
+	fn := runtime.FuncForPC(method.Func.Pointer())
+	if fn == nil {
+		return "", 0
+	}
+	return fn.FileLine(fn.Entry())
+}
+
+func (plan *suitePlan) call(hooks []*hook, info *TestInfo) {
+	for _, h := range hooks {
+		args := []reflect.Value{reflect.ValueOf(plan.catcher)}
+		if h.withInfo {
+			args = append(args, reflect.ValueOf(info))
+		}
+		h.fn.Call(args)
+	}
+}
+
+// report counts testFunc in the final report and prints its status.
+func (plan *suitePlan) report(t T, formatter Formatter, report *FinalReport, testFunc *TestFunc) {
+	report.count(testFunc)
+	if testFunc.Status == STATUS_FAIL || testFunc.Status == STATUS_FLAKY || testFunc.Status == STATUS_TIMEOUT {
+		t.Fail()
+	}
+	formatter.PrintStatus(testFunc)
+}
+
+// runSuiteHooks calls the given suite-level hooks. A panic is
+// reported as the failure of a test function named after the hook. A
+// hook calling Skip stops the following ones from being called.
+func (plan *suitePlan) runSuiteHooks(t T, formatter Formatter, report *FinalReport, hooks []*hook) bool {
+	s := plan.catcher.suite()
+	skipped := s.skipReason != ""
+	for _, h := range hooks {
+		if r := protect(func() { plan.call([]*hook{h}, nil) }); r != nil {
+			testFunc := &TestFunc{Name: h.name, Status: STATUS_FAIL, suite: s}
+			testFunc.failWith(r.filename, r.line, r.message())
+			plan.report(t, formatter, report, testFunc)
+			return false
+		}
+		if !skipped && s.skipReason != "" {
+			break
+		}
+	}
+	return true
+}
+
+// runInjections populates the suite fields, reporting the first
+// field that can't be populated.
+func (plan *suitePlan) runInjections(t T, formatter Formatter, report *FinalReport) bool {
+	if testFunc := plan.inject(); testFunc != nil {
+		plan.report(t, formatter, report, testFunc)
+		return false
+	}
+	return true
+}
+
+// runTest runs a single test case surrounded by the per-test hooks
+// and cleanups.
+func (plan *suitePlan) runTest(tc *testCase, tags []string) *TestFunc {
+	s := plan.catcher.suite()
+	testFunc := s.startTestFunc(tc.name)
+	testFunc.Tags, testFunc.Path = tags, tc.path
+	info := newTestInfo(testFunc)
+	testFunc.ctx, testFunc.cancel = context.WithCancel(context.WithValue(s.Context(), testInfoKey, info))
+
+	body := func() {
+		plan.call(plan.before, info)
+		tc.call()
+	}
+	var snapshot map[string]bool
+	if plan.leakMode() == leaksTest {
+		snapshot = goroutineSnapshot()
+	}
+
+	var c *capture
+	if *captureOutput {
+		c = startCapture(testFunc)
+	}
+
+	filename, line := tc.location()
+	info.start = time.Now()
+	if r := runGuarded(testFunc, plan.timeout(tc.name), filename, line, body); r != nil {
+		testFunc.failWith(r.filename, r.line, r.message())
+	}
+	testFunc.Duration = time.Since(info.start)
+	testFunc.cancel()
+
+	if r := protect(func() { plan.call(plan.after, info) }); r != nil {
+		testFunc.failWith(r.filename, r.line, r.message())
+	}
+	runCleanups(testFunc.cleanups, testFunc)
+	c.stop()
+	if snapshot != nil && testFunc.Status != STATUS_TIMEOUT {
+		checkLeaks(testFunc, snapshot, filename, line)
+	}
+
+	s.endTestFunc()
+
+	if testFunc.mustFail && testFunc.Status != STATUS_SKIPPED && testFunc.Status != STATUS_TIMEOUT {
+		if testFunc.Status != STATUS_FAIL {
+			testFunc.Status = STATUS_FAIL
+			testFunc.logError("The test was expected to fail")
+		} else {
+			testFunc.Status = STATUS_MUST_FAIL
+		}
+	}
+	return testFunc
+}
+
+// run executes the plan reporting the results through formatter and
+// collecting them in report. No more tests are started once ctx is
+// cancelled.
+func (plan *suitePlan) run(ctx context.Context, t T, formatter Formatter, report *FinalReport) {
+	for _, error := range plan.errors {
+		logError(error)
+		plan.report(t, formatter, report, error.TestFunc)
+	}
+
+	s := plan.catcher.suite()
+	var snapshot map[string]bool
+	if plan.leakMode() == leaksSuite {
+		snapshot = goroutineSnapshot()
+	}
+
+	var cancel context.CancelFunc
+	s.ctx, cancel = context.WithCancel(ctx)
+	if plan.runInjections(t, formatter, report) && plan.runSuiteHooks(t, formatter, report, plan.beforeAll) {
+		for _, tc := range plan.methods {
+			if ctx.Err() != nil {
+				break
+			}
+			tags := mergeTags(plan.tags[""], plan.tags[tc.name], s.tags)
+			if ok, _ := matchTags(tags); !ok {
+				continue
+			}
+			if s.skipReason != "" {
+				testFunc := s.startTestFunc(tc.name)
+				testFunc.Status, testFunc.SkipReason, testFunc.Tags, testFunc.Path = STATUS_SKIPPED, s.skipReason, tags, tc.path
+				s.endTestFunc()
+				plan.report(t, formatter, report, testFunc)
+				continue
+			}
+			if testFunc := plan.runRepeated(tc, tags, report); !testFunc.filtered {
+				plan.report(t, formatter, report, testFunc)
+			}
+		}
+		plan.runSuiteHooks(t, formatter, report, plan.afterAll)
+	}
+	cancel()
+	plan.runSuiteCleanups(t, formatter, report, snapshot)
+}
+
+// runSuiteCleanups calls the cleanups registered in BeforeAll and
+// checks for goroutines leaked by the suite since snapshot, if not
+// nil. Failures are reported under the name of the suite.
+func (plan *suitePlan) runSuiteCleanups(t T, formatter Formatter, report *FinalReport, snapshot map[string]bool) {
+	s := plan.catcher.suite()
+	suiteFunc := &TestFunc{Name: s.Name, Status: STATUS_PASS, suite: s}
+	runCleanups(s.cleanups, suiteFunc)
+	s.cleanups = nil
+	if snapshot != nil {
+		checkLeaks(suiteFunc, snapshot, "", 0)
+	}
+	if suiteFunc.Status == STATUS_FAIL {
+		plan.report(t, formatter, report, suiteFunc)
+	}
+}
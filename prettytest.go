@@ -75,10 +75,12 @@ usage examples.
 package prettytest
 
 import (
+	"context"
 	"reflect"
-	"regexp"
 	"runtime"
 	"strings"
+	"sync"
+	"time"
 )
 
 const (
@@ -87,6 +89,9 @@ const (
 	STATUS_FAIL
 	STATUS_MUST_FAIL
 	STATUS_PENDING
+	STATUS_SKIPPED
+	STATUS_FLAKY
+	STATUS_TIMEOUT
 )
 
 var (
@@ -123,15 +128,71 @@ type tCatcher interface {
 }
 
 func logError(error *Error) {
+	if error.TestFunc != nil && error.TestFunc.detached {
+		return
+	}
 	ErrorLog = append(ErrorLog, error)
 }
 
 type TestFunc struct {
 	Name, CallerName string
 	Status           int
+	SkipReason       string
+	Tags             []string
+	Path             []string
+	Steps            []*Step
+	Runs, Failures   int
+	Retries          int
+	Logs             []string
 	Assertions       []*Assertion
+	Duration         time.Duration
 	suite            *Suite
 	mustFail         bool
+	cleanups         []func()
+	filtered         bool
+	abandoned        bool
+	detached         bool
+	ctx              context.Context
+	cancel           context.CancelFunc
+}
+
+// TestInfo describes the test being run. It is passed to the
+// BeforeTest and AfterTest hooks, and to Before, After, SetupTest
+// and TearDownTest when they are declared with a *TestInfo
+// argument.
+type TestInfo struct {
+	Suite    *Suite
+	Name     string
+	testFunc *TestFunc
+	start    time.Time
+}
+
+func newTestInfo(testFunc *TestFunc) *TestInfo {
+	return &TestInfo{Suite: testFunc.suite, Name: testFunc.Name, testFunc: testFunc}
+}
+
+// Status returns the status of the test so far.
+func (info *TestInfo) Status() int {
+	return info.testFunc.Status
+}
+
+// Failed checks if the test has failed or timed out so far.
+func (info *TestInfo) Failed() bool {
+	return failed(info.testFunc)
+}
+
+// Assertions returns the assertions made by the test so far.
+func (info *TestInfo) Assertions() []*Assertion {
+	return info.testFunc.Assertions
+}
+
+// Duration returns the time spent running the test, Before hooks
+// included. It is zero before the test starts.
+func (info *TestInfo) Duration() time.Duration {
+	if info.testFunc.Duration == 0 && !info.start.IsZero() {
+		return time.Since(info.start)
+	}
+	return info.testFunc.Duration
 }
 
 type T interface {
@@ -142,15 +203,35 @@ type Suite struct {
 	T             T
 	Package, Name string
 	TestFuncs     map[string]*TestFunc
+	running       *TestFunc
+	mu            sync.Mutex
+	cleanups      []func()
+	skipReason    string
+	tags          []string
+	ctx           context.Context
+	specs         *specContainer
+	declared      []*testCase
+	goroutines    map[string]*TestFunc
+	abandoned     int
 }
 
 func (s *Suite) setT(t T)                        { s.T = t }
-func (s *Suite) init()                           { s.TestFuncs = make(map[string]*TestFunc) }
 func (s *Suite) suite() *Suite                   { return s }
 func (s *Suite) setPackageName(name string)      { s.Package = name }
 func (s *Suite) setSuiteName(name string)        { s.Name = name }
 func (s *Suite) testFuncs() map[string]*TestFunc { return s.TestFuncs }
 
+func (s *Suite) init() {
+	s.TestFuncs = make(map[string]*TestFunc)
+	s.running = nil
+	s.skipReason = ""
+	s.tags = nil
+	s.mu.Lock()
+	s.goroutines = nil
+	s.abandoned = 0
+	s.mu.Unlock()
+}
+
 func (s *Suite) appendTestFuncFromMethod(method *callerInfo) *TestFunc {
 	name := method.name
 	if _, ok := s.TestFuncs[name]; !ok {
@@ -163,7 +244,86 @@ func (s *Suite) appendTestFuncFromMethod(method *callerInfo) *TestFunc {
 	return s.TestFuncs[name]
 }
 
+// startTestFunc creates the TestFunc for the named test and makes it
+// the target of the assertions until endTestFunc is called.
+func (s *Suite) startTestFunc(name string) *TestFunc {
+	testFunc := &TestFunc{
+		Name:   name,
+		Status: STATUS_NO_ASSERTIONS,
+		suite:  s,
+	}
+	s.TestFuncs[name] = testFunc
+	s.mu.Lock()
+	s.running = testFunc
+	s.mu.Unlock()
+	return testFunc
+}
+
+func (s *Suite) endTestFunc() {
+	s.mu.Lock()
+	s.running = nil
+	s.mu.Unlock()
+}
+
+// current returns the test function being run, or nil outside of
+// test methods. It is safe to call from the goroutines started by the
+// test. Tests abandoned by runGuarded, and the goroutines they
+// started, get a detached copy of their test function instead, so
+// that they can't affect the tests run after them.
+func (s *Suite) current() *TestFunc {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.goroutines) > 0 {
+		id := goroutineID()
+		testFunc, ok := s.goroutines[id]
+		if !ok && s.abandoned > 0 {
+			testFunc, ok = s.goroutines[creatorID()]
+		}
+		if ok && testFunc.abandoned {
+			return &TestFunc{Name: testFunc.Name, Status: STATUS_PASS, suite: s, ctx: testFunc.ctx, detached: true}
+		}
+		if ok {
+			return testFunc
+		}
+	}
+	return s.running
+}
+
+// bind makes testFunc the current test of the calling goroutine.
+func (s *Suite) bind(testFunc *TestFunc) string {
+	id := goroutineID()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.goroutines == nil {
+		s.goroutines = make(map[string]*TestFunc)
+	}
+	s.goroutines[id] = testFunc
+	return id
+}
+
+// unbind undoes bind once the goroutine id has finished running its
+// test, unless the test was abandoned.
+func (s *Suite) unbind(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if testFunc, ok := s.goroutines[id]; ok && !testFunc.abandoned {
+		delete(s.goroutines, id)
+	}
+}
+
+// abandon marks testFunc as abandoned: its goroutine keeps running
+// but its assertions are discarded.
+func (s *Suite) abandon(testFunc *TestFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	testFunc.abandoned = true
+	s.abandoned++
+}
+
 func (s *Suite) currentTestFunc() *TestFunc {
+	if current := s.current(); current != nil {
+		return current
+	}
 	callerName := newCallerInfo(3).name
 	if _, ok := s.TestFuncs[callerName]; !ok {
 		s.TestFuncs[callerName] = &TestFunc{
@@ -174,17 +334,49 @@ func (s *Suite) currentTestFunc() *TestFunc {
 	return s.TestFuncs[callerName]
 }
 
+// late reports whether the calling goroutine is, or was started by,
+// the goroutine of testFunc after runGuarded abandoned it. s.mu must
+// be held.
+func (s *Suite) late(testFunc *TestFunc) bool {
+	if !testFunc.abandoned {
+		return false
+	}
+	bound, ok := s.goroutines[goroutineID()]
+	if !ok {
+		bound, ok = s.goroutines[creatorID()]
+	}
+	return ok && bound == testFunc
+}
+
+// record calls write, which updates testFunc or ErrorLog, under the
+// lock of the suite. Once the test is abandoned, the writes of its
+// goroutines are dropped, so that the runner can report it safely.
+func (testFunc *TestFunc) record(write func()) {
+	s := testFunc.suite
+	if s == nil {
+		write()
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !testFunc.detached && !s.late(testFunc) {
+		write()
+	}
+}
+
 func (testFunc *TestFunc) resetLastError() {
-	if len(ErrorLog) > 0 {
-		ErrorLog[len(ErrorLog)-1].Assertion.Passed = true
-		ErrorLog = append(ErrorLog[:len(ErrorLog)-1])
-		testFunc.Status = STATUS_PASS
-		for i := 0; i < len(testFunc.Assertions); i++ {
-			if !testFunc.Assertions[i].Passed {
-				testFunc.Status = STATUS_FAIL
+	testFunc.record(func() {
+		if len(ErrorLog) > 0 {
+			ErrorLog[len(ErrorLog)-1].Assertion.Passed = true
+			ErrorLog = ErrorLog[:len(ErrorLog)-1]
+			testFunc.Status = STATUS_PASS
+			for i := 0; i < len(testFunc.Assertions); i++ {
+				if !testFunc.Assertions[i].Passed {
+					testFunc.Status = STATUS_FAIL
+				}
 			}
 		}
-	}
+	})
 }
 
 func (testFunc *TestFunc) logError(message string) {
@@ -193,8 +385,28 @@ func (testFunc *TestFunc) logError(message string) {
 	logError(error)
 }
 
+// failWith marks the test function as failed, logging message as
+// if it was the error of an assertion made at filename:line.
+func (testFunc *TestFunc) failWith(filename string, line int, message string) *Assertion {
+	assertion := &Assertion{
+		Filename:     filename,
+		Line:         line,
+		ErrorMessage: message,
+		suite:        testFunc.suite,
+		testFunc:     testFunc,
+	}
+	testFunc.appendAssertion(assertion)
+	assertion.fail()
+	return assertion
+}
+
 func (testFunc *TestFunc) appendAssertion(assertion *Assertion) *Assertion {
-	testFunc.Assertions = append(testFunc.Assertions, assertion)
+	testFunc.record(func() {
+		if testFunc.Status == STATUS_NO_ASSERTIONS {
+			testFunc.Status = STATUS_PASS
+		}
+		testFunc.Assertions = append(testFunc.Assertions, assertion)
+	})
 	return assertion
 }
 
@@ -212,7 +424,10 @@ func (s *Suite) setup(errorMessage string, customMessages []string) *Assertion {
 	// Retrieve the testing method
 	callerInfo := newCallerInfo(3)
 	assertionName := newCallerInfo(2).name
-	testFunc := s.appendTestFuncFromMethod(callerInfo)
+	testFunc := s.current()
+	if testFunc == nil {
+		testFunc = s.appendTestFuncFromMethod(callerInfo)
+	}
 	assertion := &Assertion{
 		Line:         callerInfo.line,
 		Filename:     callerInfo.fn,
@@ -236,115 +451,62 @@ func RunWithFormatter(t T, formatter Formatter, suites ...tCatcher) {
 	run(t, formatter, suites...)
 }
 
+// prepare resets s and names it after its type.
+func prepare(t T, s tCatcher) {
+	s.setT(t)
+	s.init()
+
+	iType := reflect.TypeOf(s)
+	splits := strings.Split(iType.String(), ".")
+	s.setPackageName(splits[0][1:])
+	s.setSuiteName(splits[1])
+}
+
 // Run tests. Use default formatter.
 func run(t T, formatter Formatter, suites ...tCatcher) {
-	var (
-		beforeAllFound, afterAllFound                                                    bool
-		beforeAll, afterAll, before, after                                               reflect.Value
-		totalPassed, totalFailed, totalPending, totalNoAssertions, totalExpectedFailures int
-	)
+	report := new(FinalReport)
 
 	ErrorLog = make([]*Error, 0)
+	resetFixtures()
 	//	flag.Parse()
 
+	envErr := applyEnv()
+	plans := make([]*suitePlan, 0, len(suites))
 	for _, s := range suites {
-		beforeAll, afterAll, before, after = reflect.Value{}, reflect.Value{}, reflect.Value{}, reflect.Value{}
-		s.setT(t)
-		s.init()
-
-		iType := reflect.TypeOf(s)
-		splits := strings.Split(iType.String(), ".")
-		s.setPackageName(splits[0][1:])
-		s.setSuiteName(splits[1])
-		formatter.PrintSuiteInfo(s.suite())
-
-		// search for Before and After methods
-		for i := 0; i < iType.NumMethod(); i++ {
-			method := iType.Method(i)
-			if ok, _ := regexp.MatchString("^BeforeAll", method.Name); ok {
-				if !beforeAllFound {
-					beforeAll = method.Func
-					beforeAllFound = true
-					continue
-				}
-			}
-			if ok, _ := regexp.MatchString("^AfterAll", method.Name); ok {
-				if !afterAllFound {
-					afterAll = method.Func
-					afterAllFound = true
-					continue
-				}
-			}
-			if ok, _ := regexp.MatchString("^Before", method.Name); ok {
-				before = method.Func
-			}
-			if ok, _ := regexp.MatchString("^After", method.Name); ok {
-				after = method.Func
-			}
-		}
-
-		if beforeAll.IsValid() {
-			beforeAll.Call([]reflect.Value{reflect.ValueOf(s)})
-		}
+		prepare(t, s)
+		plans = append(plans, newSuitePlan(s, formatter))
+	}
+	if len(plans) > 0 {
+		plans[0].checkOptions(envErr)
+	}
 
-		for i := 0; i < iType.NumMethod(); i++ {
-			method := iType.Method(i)
-			if filterMethod(method.Name) {
-				if ok, _ := regexp.MatchString(formatter.AllowedMethodsPattern(), method.Name); ok {
-					if before.IsValid() {
-						before.Call([]reflect.Value{reflect.ValueOf(s)})
-					}
-
-					method.Func.Call([]reflect.Value{reflect.ValueOf(s)})
-
-					if after.IsValid() {
-						after.Call([]reflect.Value{reflect.ValueOf(s)})
-					}
-
-					testFunc, ok := s.testFuncs()[method.Name]
-					if !ok {
-						testFunc = &TestFunc{Name: method.Name, Status: STATUS_NO_ASSERTIONS}
-					}
-
-					if testFunc.mustFail {
-						if testFunc.Status != STATUS_FAIL {
-							testFunc.Status = STATUS_FAIL
-							testFunc.logError("The test was expected to fail")
-						} else {
-							testFunc.Status = STATUS_MUST_FAIL
-						}
-					}
-
-					switch testFunc.Status {
-					case STATUS_PASS:
-						totalPassed++
-					case STATUS_FAIL:
-						totalFailed++
-						t.Fail()
-					case STATUS_MUST_FAIL:
-						totalExpectedFailures++
-					case STATUS_PENDING:
-						totalPending++
-					case STATUS_NO_ASSERTIONS:
-						totalNoAssertions++
-					}
-					formatter.PrintStatus(testFunc)
-				}
+	if err := selectAt(plans); err != nil && len(plans) > 0 {
+		plans[0].addError("At", "", 0, err.Error())
+	}
+	_, report.SkippedByFocus = applyFocus(plans)
 
-			}
+	seed, shuffle, err := shuffleSeed()
+	if err != nil && len(plans) > 0 {
+		plans[0].addError("Shuffle", "", 0, err.Error())
+	}
+	if shuffle {
+		report.Shuffled, report.Seed = true, seed
+		shufflePlans(plans, seed)
+	}
 
-		}
+	ctx := interruptContext()
 
-		if afterAll.IsValid() {
-			afterAll.Call([]reflect.Value{reflect.ValueOf(s)})
+	for _, plan := range plans {
+		if ctx.Err() != nil {
+			report.Interrupted = true
+			t.Fail()
+			break
 		}
+		formatter.PrintSuiteInfo(plan.catcher.suite())
+		plan.run(ctx, t, formatter, report)
+		report.Interrupted = ctx.Err() != nil
 
 		formatter.PrintErrorLog(ErrorLog)
-		formatter.PrintFinalReport(&FinalReport{Passed: totalPassed,
-			Failed:           totalFailed,
-			Pending:          totalPending,
-			ExpectedFailures: totalExpectedFailures,
-			NoAssertions:     totalNoAssertions,
-		})
+		formatter.PrintFinalReport(report)
 	}
 }
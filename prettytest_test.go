@@ -26,9 +26,21 @@ WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE SOFTWARE.
 package prettytest
 
 import (
+	"context"
+	"flag"
+	"fmt"
 	"io/ioutil"
+	"log"
+	"math"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	gocheck "gopkg.in/check.v1"
 )
@@ -39,6 +51,140 @@ type testSuite struct{ Suite }
 
 type beforeAfterSuite struct{ Suite }
 type bddFormatterSuite struct{ Suite }
+type exactHooksSuite struct {
+	Suite
+	beforeAll, before, afterburner, setupTest int
+}
+type misconfiguredSuite struct{ Suite }
+type cleanupSuite struct {
+	Suite
+	calls []string
+	dirs  []string
+}
+type skipSuite struct{ Suite }
+type skippedSuite struct {
+	Suite
+	setUp bool
+}
+type taggedSuite struct {
+	Suite
+	ran []string
+}
+type shuffledSuite struct {
+	Suite
+	order []string
+}
+type flakySuite struct {
+	Suite
+	runs map[string]int
+}
+type timeoutSuite struct {
+	Suite
+	afterRan, failed bool
+}
+type lateSuite struct {
+	Suite
+	late sync.WaitGroup
+}
+type busySuite struct {
+	Suite
+	stop chan struct{}
+	done sync.WaitGroup
+}
+type contextSuite struct {
+	Suite
+	ctx context.Context
+}
+type interruptedSuite struct {
+	Suite
+	ran []string
+}
+type logSuite struct{ Suite }
+type benchmarkSuite struct {
+	Suite
+	beforeAll, before int
+	n                 int
+}
+type propertySuite struct{ Suite }
+type propertyTree struct {
+	Left, Right *propertyTree
+	Children    []propertyTree
+	Labels      map[string]*propertyTree
+}
+type fuzzSuite struct {
+	Suite
+	beforeAll, inputs int
+}
+type httpSuite struct{ Suite }
+type fixtureSuite struct{ Suite }
+type injectedCounter struct{ closed bool }
+type injectedSuite struct {
+	Suite
+	Dir     string           `pt:"tempdir"`
+	Server  *httptest.Server `pt:"httpserver=ServeHello"`
+	User    fixtureUser      `pt:"fixture=user.json"`
+	Counter *injectedCounter
+	Ignored *injectedCounter `pt:"-"`
+	seen    bool
+}
+type misinjectedSuite struct {
+	Suite
+	dir    string `pt:"tempdir"`
+	Server string `pt:"httpserver"`
+	Other  int    `pt:"unknown"`
+}
+type sharedStack interface {
+	Push(n int)
+	Len() int
+}
+type sliceStack []int
+type listStack struct{ n int }
+type stackExamples struct {
+	Shared
+	Stack sharedStack
+}
+type sliceStackSuite struct {
+	Suite
+	Examples stackExamples
+}
+type listStackSuite struct {
+	Suite
+	Examples *stackExamples
+}
+type hiddenSharedSuite struct {
+	Suite
+	examples stackExamples
+}
+type specSuite struct {
+	Suite
+	calls []string
+}
+type focusedSpecSuite struct {
+	Suite
+	run []string
+}
+type focusedSuite struct {
+	Suite
+	run []string
+}
+type scenarioSuite struct {
+	Suite
+	ran []string
+}
+type leakSuite struct {
+	Suite
+	stop chan bool
+}
+type testInfoSuite struct {
+	Suite
+	started    []string
+	failed     map[string]bool
+	assertions map[string]int
+}
+
+type fakeT struct{ failed bool }
+
+func (t *fakeT) Fail() { t.failed = true }
 
 func (suite *testSuite) TestNoAssertions() {}
 
@@ -92,8 +238,9 @@ func (suite *testSuite) TestNil() {
 }
 
 func (suite *testSuite) TestPath() {
-	ioutil.WriteFile("./testfile", nil, 0600)
-	suite.Path("testfile")
+	path := filepath.Join(suite.TempDir(), "testfile")
+	ioutil.WriteFile(path, nil, 0600)
+	suite.Path(path)
 	suite.Not(suite.Path("foo"))
 }
 
@@ -101,10 +248,6 @@ func (suite *testSuite) TestPending() {
 	suite.Pending()
 }
 
-func (suite *testSuite) After() {
-	os.Remove("testfile")
-}
-
 func (suite *beforeAfterSuite) Before() {
 	state += 2
 	beforeState++
@@ -147,6 +290,1149 @@ func TestPrettyTest(t *testing.T) {
 	}
 }
 
+func (suite *exactHooksSuite) BeforeAll()       { suite.beforeAll++ }
+func (suite *exactHooksSuite) Before()          { suite.before++ }
+func (suite *exactHooksSuite) SetupTest()       { suite.setupTest++ }
+func (suite *exactHooksSuite) AfterburnerTest() { suite.afterburner++ }
+
+func (suite *exactHooksSuite) TestHooksAreMatchedExactly() {
+	suite.Equal(1, suite.beforeAll)
+	suite.Equal(1, suite.before)
+	suite.Equal(1, suite.setupTest)
+	suite.Equal(0, suite.afterburner)
+}
+
+func (suite *misconfiguredSuite) Before(name string) {}
+
+func (suite *misconfiguredSuite) TestWithArguments(n int) {}
+
+func (suite *misconfiguredSuite) TestValid() {
+	suite.True(true)
+}
+
+func TestSuitePlan(t *testing.T) {
+	initialBeforeAllState := beforeAllState
+	Run(
+		t,
+		new(exactHooksSuite),
+		new(beforeAfterSuite),
+	)
+	if beforeAllState != initialBeforeAllState+1 {
+		t.Errorf("BeforeAll of the second suite should run, beforeAllState was %d\n", beforeAllState)
+	}
+
+	ft := new(fakeT)
+	Run(ft, new(misconfiguredSuite))
+	if !ft.failed {
+		t.Error("Misconfigured methods should be reported as failures")
+	}
+	if len(ErrorLog) != 2 {
+		t.Errorf("Expected 2 misconfiguration errors but got %d\n", len(ErrorLog))
+	}
+}
+
+func (suite *testInfoSuite) BeforeAll() {
+	suite.failed = make(map[string]bool)
+	suite.assertions = make(map[string]int)
+}
+
+func (suite *testInfoSuite) BeforeTest(info *TestInfo) {
+	suite.started = append(suite.started, info.Name)
+}
+
+func (suite *testInfoSuite) AfterTest(info *TestInfo) {
+	suite.failed[info.Name] = info.Failed()
+	suite.assertions[info.Name] = len(info.Assertions())
+}
+
+func (suite *testInfoSuite) TestFailing() {
+	suite.True(false)
+	suite.MustFail()
+}
+
+func (suite *testInfoSuite) TestName() {
+	suite.Equal("TestName", suite.started[len(suite.started)-1])
+}
+
+func TestTestInfoHooks(t *testing.T) {
+	suite := new(testInfoSuite)
+	Run(t, suite)
+	if len(suite.started) != 2 {
+		t.Errorf("BeforeTest should run for each test but ran %d times\n", len(suite.started))
+	}
+	if !suite.failed["TestFailing"] || suite.failed["TestName"] {
+		t.Errorf("AfterTest received wrong statuses: %v\n", suite.failed)
+	}
+	if suite.assertions["TestName"] != 1 {
+		t.Errorf("AfterTest should see 1 assertion for TestName but saw %d\n", suite.assertions["TestName"])
+	}
+}
+
+func (suite *cleanupSuite) BeforeAll() {
+	suite.Cleanup(func() { suite.calls = append(suite.calls, "suite") })
+	suite.dirs = append(suite.dirs, suite.TempDir())
+}
+
+func (suite *cleanupSuite) TestLIFO() {
+	suite.Cleanup(func() { suite.calls = append(suite.calls, "first") })
+	suite.Cleanup(func() { suite.calls = append(suite.calls, "second") })
+	suite.True(true)
+}
+
+func (suite *cleanupSuite) TestPanicking() {
+	suite.MustFail()
+	suite.Cleanup(func() { suite.calls = append(suite.calls, "panicked") })
+	panic("This test should be marked as failed")
+}
+
+func (suite *cleanupSuite) TestTempDir() {
+	dir := suite.TempDir()
+	suite.dirs = append(suite.dirs, dir)
+	suite.Path(dir)
+}
+
+func TestCleanup(t *testing.T) {
+	suite := new(cleanupSuite)
+	Run(t, suite)
+	expected := []string{"second", "first", "panicked", "suite"}
+	if strings.Join(suite.calls, ",") != strings.Join(expected, ",") {
+		t.Errorf("Cleanups should run in LIFO order %v but ran %v\n", expected, suite.calls)
+	}
+	for _, dir := range suite.dirs {
+		if _, err := os.Stat(dir); !os.IsNotExist(err) {
+			t.Errorf("Temporary directory %s should have been removed\n", dir)
+		}
+	}
+}
+
+func (suite *skipSuite) TestSkip() {
+	suite.Skip("Not supported")
+	suite.Error("This should not be reached")
+}
+
+func (suite *skipSuite) TestSkipIf() {
+	suite.SkipIf(false, "Never skipped")
+	suite.True(true)
+}
+
+func (suite *skipSuite) TestSkipUnlessEnv() {
+	suite.SkipUnlessEnv("PRETTYTEST_UNSET_VARIABLE")
+	suite.Error("This should not be reached")
+}
+
+func (suite *skippedSuite) BeforeAll() {
+	suite.Skip("The whole suite is skipped")
+}
+
+func (suite *skippedSuite) SetupSuite() {
+	suite.setUp = true
+}
+
+func (suite *skippedSuite) TestNotRun() {
+	suite.Error("This should not be reached")
+}
+
+func TestSkip(t *testing.T) {
+	suite, skipped := new(skipSuite), new(skippedSuite)
+	Run(t, suite, skipped)
+	expected := map[string]string{
+		"TestSkip":          "Not supported",
+		"TestSkipUnlessEnv": "$PRETTYTEST_UNSET_VARIABLE is not set",
+	}
+	for name, reason := range expected {
+		if testFunc := suite.TestFuncs[name]; testFunc.Status != STATUS_SKIPPED || testFunc.SkipReason != reason {
+			t.Errorf("%s should be skipped with reason %q but got status %d and reason %q\n", name, reason, testFunc.Status, testFunc.SkipReason)
+		}
+	}
+	if status := suite.TestFuncs["TestSkipIf"].Status; status != STATUS_PASS {
+		t.Errorf("TestSkipIf should pass but got status %d\n", status)
+	}
+	if status := skipped.TestFuncs["TestNotRun"].Status; status != STATUS_SKIPPED {
+		t.Errorf("Tests of a suite skipped in BeforeAll should be skipped but got status %d\n", status)
+	}
+	if skipped.setUp {
+		t.Error("SetupSuite should not run after BeforeAll calls Skip")
+	}
+}
+
+func (suite *taggedSuite) Tags() map[string][]string {
+	return map[string][]string{
+		"":         {"unit"},
+		"TestSlow": {"slow"},
+		"TestFast": {"fast"},
+	}
+}
+
+func (suite *taggedSuite) TestSlow() {
+	suite.ran = append(suite.ran, "TestSlow")
+	suite.True(true)
+}
+
+func (suite *taggedSuite) TestFast() {
+	suite.ran = append(suite.ran, "TestFast")
+	suite.True(true)
+}
+
+func (suite *taggedSuite) TestDynamic() {
+	suite.Tag("flaky")
+	suite.ran = append(suite.ran, "TestDynamic")
+	suite.True(true)
+}
+
+func TestTagExpressions(t *testing.T) {
+	tags := map[string]bool{"integration": true, "db": true}
+	expressions := map[string]bool{
+		"integration":                 true,
+		"!integration":                false,
+		"integration && !slow":        true,
+		"slow || db":                  true,
+		"slow, fast":                  false,
+		"!(integration && db)":        false,
+		"(slow || integration) && db": true,
+	}
+	for text, expected := range expressions {
+		expr, err := parseTagExpr(text)
+		if err != nil {
+			t.Errorf("Unexpected error parsing %q: %s\n", text, err)
+			continue
+		}
+		if expr.eval(tags) != expected {
+			t.Errorf("%q should evaluate to %v\n", text, expected)
+		}
+	}
+	for _, text := range []string{"", "a &&", "(a", "a b", "a & b"} {
+		if _, err := parseTagExpr(text); err == nil {
+			t.Errorf("Expected an error parsing %q\n", text)
+		}
+	}
+}
+
+func TestTags(t *testing.T) {
+	*tagsToRun, *tagsToSkip = "unit && !slow", "flaky"
+	defer func() { *tagsToRun, *tagsToSkip = "", "" }()
+	suite := new(taggedSuite)
+	Run(t, suite)
+	if strings.Join(suite.ran, ",") != "TestFast" {
+		t.Errorf("Only TestFast should run but ran %v\n", suite.ran)
+	}
+	if tags := suite.TestFuncs["TestFast"].Tags; strings.Join(tags, ",") != "fast,unit" {
+		t.Errorf("TestFast should be tagged fast and unit but was tagged %v\n", tags)
+	}
+
+	*tagsToRun, *tagsToSkip = "unit &&", ""
+	ft := new(fakeT)
+	Run(ft, new(taggedSuite), new(taggedSuite))
+	if !ft.failed || len(ErrorLog) != 1 {
+		t.Errorf("An invalid tag expression should be reported once but got %d errors\n", len(ErrorLog))
+	}
+}
+
+func TestPatterns(t *testing.T) {
+	tests := []struct {
+		patterns, suite, name string
+		expected              bool
+	}{
+		{"", "parserSuite", "TestParse", true},
+		{"Parse", "parserSuite", "TestParse", true},
+		{"Lex,Parse", "parserSuite", "TestParse", true},
+		{"Lex", "parserSuite", "TestParse", false},
+		{"parser/Parse$", "parserSuite", "TestParse", true},
+		{"lexer/Parse", "parserSuite", "TestParse", false},
+		{"spec/Parser/empty", "specSuite", "Parser/with empty input/returns EOF", true},
+		{"spec/Parser/full", "specSuite", "Parser/with empty input/returns EOF", false},
+		{"parser/Parse/extra", "parserSuite", "TestParse", false},
+	}
+	for _, test := range tests {
+		if ok, err := matchPatterns(test.patterns, test.suite, test.name); err != nil || ok != test.expected {
+			t.Errorf("%q should match %s/%s: %v, got %v (%v)\n", test.patterns, test.suite, test.name, test.expected, ok, err)
+		}
+	}
+	if _, err := matchPatterns("parser/(", "parserSuite", "TestParse"); err == nil {
+		t.Error("Expected an error for an invalid pattern")
+	}
+}
+
+func TestFilter(t *testing.T) {
+	*testToRun, *testsToSkip = "sliceStackSuite/Examples,listStackSuite/Examples.TestEmpty", "TestPush"
+	sliceSuite, listSuite := new(sliceStackSuite), new(listStackSuite)
+	Run(t, sliceSuite, listSuite)
+	*testToRun, *testsToSkip = "", ""
+	if len(sliceSuite.TestFuncs) != 1 || len(listSuite.TestFuncs) != 1 {
+		t.Errorf("Only Examples.TestEmpty should run but ran %d and %d tests\n", len(sliceSuite.TestFuncs), len(listSuite.TestFuncs))
+	}
+
+	*testsToSkip = "Test("
+	ft := new(fakeT)
+	Run(ft, new(sliceStackSuite), new(listStackSuite))
+	*testsToSkip = ""
+	if !ft.failed || len(ErrorLog) != 1 {
+		t.Errorf("An invalid test pattern should be reported once but got %d errors\n", len(ErrorLog))
+	}
+
+	suite := new(specSuite)
+	prepare(t, suite)
+	plan := newSuitePlan(suite, new(TDDFormatter))
+	defer func() { *testAt = "" }()
+	for _, tc := range plan.methods {
+		if tc.name != "Stack/after a push/has one element" && tc.name != "TestDescribeOutsideSpecs" {
+			continue
+		}
+		filename, line := tc.location()
+		*testAt = fmt.Sprintf("%s:%d", filepath.Base(filename), line+1)
+		suite = new(specSuite)
+		Run(t, suite)
+		if _, ok := suite.TestFuncs[tc.name]; !ok || len(suite.TestFuncs) != 1 {
+			t.Errorf("Only %s should run with -pt.at=%s but ran %d tests\n", tc.name, *testAt, len(suite.TestFuncs))
+		}
+	}
+
+	*testAt = "prettytest_test.go:1"
+	ft = new(fakeT)
+	Run(ft, new(specSuite))
+	if !ft.failed {
+		t.Error("A location without tests should be reported as a failure")
+	}
+}
+
+func TestEnvironment(t *testing.T) {
+	if name := envName("pt.skip-tags"); name != "PRETTYTEST_SKIP_TAGS" {
+		t.Errorf("Expected the option to be set by PRETTYTEST_SKIP_TAGS but got %s\n", name)
+	}
+
+	os.Setenv("PRETTYTEST_RUN", "TestEmpty")
+	suite := new(sliceStackSuite)
+	Run(t, suite)
+	os.Unsetenv("PRETTYTEST_RUN")
+	if _, ok := suite.TestFuncs["Examples.TestEmpty"]; !ok || len(suite.TestFuncs) != 1 {
+		t.Errorf("Only Examples.TestEmpty should run with PRETTYTEST_RUN but ran %d tests\n", len(suite.TestFuncs))
+	}
+	suite = new(sliceStackSuite)
+	Run(t, suite)
+	if len(suite.TestFuncs) != 2 {
+		t.Errorf("Unsetting PRETTYTEST_RUN should restore the default but ran %d tests\n", len(suite.TestFuncs))
+	}
+
+	os.Setenv("PRETTYTEST_COUNT", "twice")
+	defer os.Unsetenv("PRETTYTEST_COUNT")
+	ft := new(fakeT)
+	Run(ft, new(sliceStackSuite), new(sliceStackSuite))
+	if !ft.failed || len(ErrorLog) != 1 || !strings.Contains(ErrorLog[0].Assertion.ErrorMessage, "PRETTYTEST_COUNT") {
+		t.Errorf("Invalid values in the environment should be reported once as failures but got %d errors\n", len(ErrorLog))
+	}
+}
+
+func (suite *shuffledSuite) BeforeTest(info *TestInfo) {
+	suite.order = append(suite.order, info.Name)
+}
+
+func (suite *shuffledSuite) TestA() { suite.True(true) }
+func (suite *shuffledSuite) TestB() { suite.True(true) }
+func (suite *shuffledSuite) TestC() { suite.True(true) }
+func (suite *shuffledSuite) TestD() { suite.True(true) }
+func (suite *shuffledSuite) TestE() { suite.True(true) }
+
+func TestShuffle(t *testing.T) {
+	*shuffle = "42"
+	defer func() { *shuffle = "off" }()
+	first, second := new(shuffledSuite), new(shuffledSuite)
+	Run(t, first)
+	Run(t, second)
+	if strings.Join(first.order, ",") != strings.Join(second.order, ",") {
+		t.Errorf("The same seed should give the same order but got %v and %v\n", first.order, second.order)
+	}
+	if len(first.order) != 5 {
+		t.Errorf("All the tests should run but ran %v\n", first.order)
+	}
+}
+
+func (suite *flakySuite) BeforeAll() {
+	suite.runs = make(map[string]int)
+}
+
+func (suite *flakySuite) BeforeTest(info *TestInfo) {
+	suite.runs[info.Name]++
+}
+
+func (suite *flakySuite) TestFlaky() {
+	suite.Equal(0, suite.runs["TestFlaky"]%2)
+}
+
+func (suite *flakySuite) TestStable() {
+	suite.True(true)
+}
+
+func TestRepeat(t *testing.T) {
+	*repeatCount = 4
+	defer func() { *repeatCount = 1 }()
+	ft, suite := new(fakeT), new(flakySuite)
+	Run(ft, suite)
+	if !ft.failed {
+		t.Error("A flaky test should fail the run")
+	}
+	if testFunc := suite.TestFuncs["TestFlaky"]; testFunc.Status != STATUS_FLAKY || testFunc.Runs != 4 || testFunc.Failures != 2 {
+		t.Errorf("TestFlaky should be flaky with 2/4 failures but got status %d and %d/%d failures\n", testFunc.Status, testFunc.Failures, testFunc.Runs)
+	}
+	if status := suite.TestFuncs["TestStable"].Status; status != STATUS_PASS {
+		t.Errorf("TestStable should pass but got status %d\n", status)
+	}
+
+	*repeatCount, *untilFail = 1, true
+	defer func() { *untilFail = false }()
+	suite = new(flakySuite)
+	Run(ft, suite)
+	if suite.runs["TestFlaky"] != 1 || suite.runs["TestStable"] != untilFailLimit {
+		t.Errorf("-pt.until-fail should stop at the first failure but ran %v\n", suite.runs)
+	}
+}
+
+func TestRetries(t *testing.T) {
+	*maxRetries = 1
+	defer func() { *maxRetries = 0 }()
+	suite := new(flakySuite)
+	Run(t, suite)
+	if testFunc := suite.TestFuncs["TestFlaky"]; testFunc.Status != STATUS_PASS || testFunc.Retries != 1 {
+		t.Errorf("TestFlaky should pass after 1 retry but got status %d after %d retries\n", testFunc.Status, testFunc.Retries)
+	}
+}
+
+func (suite *timeoutSuite) Timeouts() map[string]time.Duration {
+	return map[string]time.Duration{"TestHang": 50 * time.Millisecond}
+}
+
+func (suite *timeoutSuite) After() {
+	suite.afterRan = true
+}
+
+func (suite *timeoutSuite) AfterTest(info *TestInfo) {
+	suite.failed = info.Failed() && suite.Failed()
+}
+
+func (suite *timeoutSuite) TestHang() {
+	<-suite.Context().Done()
+}
+
+func TestTimeout(t *testing.T) {
+	ft, suite := new(fakeT), new(timeoutSuite)
+	Run(ft, suite)
+	if !ft.failed {
+		t.Error("A test that times out should fail the run")
+	}
+	if status := suite.TestFuncs["TestHang"].Status; status != STATUS_TIMEOUT {
+		t.Errorf("TestHang should time out but got status %d\n", status)
+	}
+	if !suite.afterRan {
+		t.Error("After should run when a test times out")
+	}
+	if !suite.failed {
+		t.Error("A test that times out should be seen as failed by AfterTest")
+	}
+	if len(ErrorLog) != 1 || !strings.Contains(ErrorLog[0].Assertion.ErrorMessage, "TestHang") {
+		t.Error("The stack of the timed out test should be logged")
+	}
+}
+
+func (suite *lateSuite) Timeouts() map[string]time.Duration {
+	return map[string]time.Duration{"TestA": 20 * time.Millisecond}
+}
+
+func (suite *lateSuite) TestA() {
+	suite.late.Add(2)
+	go func() {
+		defer suite.late.Done()
+		time.Sleep(60 * time.Millisecond)
+		suite.True(false, "late failure from a goroutine of TestA")
+	}()
+	time.Sleep(40 * time.Millisecond)
+	suite.True(false, "late failure from TestA")
+	suite.late.Done()
+}
+
+func (suite *lateSuite) TestB() {
+	time.Sleep(80 * time.Millisecond)
+	suite.True(true)
+}
+
+func TestLateAssertions(t *testing.T) {
+	ft, suite := new(fakeT), new(lateSuite)
+	Run(ft, suite)
+	suite.late.Wait()
+	if status := suite.TestFuncs["TestB"].Status; status != STATUS_PASS {
+		t.Errorf("TestB should pass but got status %d\n", status)
+	}
+	if len(suite.TestFuncs["TestB"].Assertions) != 1 {
+		t.Error("The assertions of a timed out test should not be attributed to the next test")
+	}
+	for _, error := range ErrorLog {
+		if strings.Contains(error.Assertion.ErrorMessage, "late failure") {
+			t.Errorf("Late assertions should be discarded but %q was logged\n", error.Assertion.ErrorMessage)
+		}
+	}
+}
+
+func (suite *busySuite) Timeouts() map[string]time.Duration {
+	return map[string]time.Duration{"TestBusy": 20 * time.Millisecond}
+}
+
+func (suite *busySuite) TestBusy() {
+	suite.done.Add(1)
+	defer suite.done.Done()
+	for {
+		select {
+		case <-suite.stop:
+			return
+		default:
+			suite.True(true)
+			suite.Equal(1, 2, "busy failure")
+		}
+	}
+}
+
+func (suite *busySuite) TestNext() {
+	suite.True(true)
+}
+
+// TestBusyLateAssertions is meant to be run with -race: the runner
+// must not share the TestFunc of a timed out test with its goroutine,
+// which keeps asserting. It is repeated to widen the window.
+func TestBusyLateAssertions(t *testing.T) {
+	for i := 0; i < 5; i++ {
+		ft, suite := new(fakeT), &busySuite{stop: make(chan struct{})}
+		Run(ft, suite)
+		testFunc := suite.TestFuncs["TestBusy"]
+		status, assertions := testFunc.Status, len(testFunc.Assertions)
+		errors := len(ErrorLog)
+		time.Sleep(10 * time.Millisecond)
+		if testFunc.Status != status || len(testFunc.Assertions) != assertions || len(ErrorLog) != errors {
+			t.Error("A timed out test should not change its results after being abandoned")
+		}
+		close(suite.stop)
+		suite.done.Wait()
+		if status != STATUS_TIMEOUT || len(suite.TestFuncs["TestNext"].Assertions) != 1 {
+			t.Errorf("TestBusy should time out without affecting TestNext but got status %d\n", status)
+		}
+	}
+}
+
+func (suite *contextSuite) TestContext() {
+	suite.ctx = suite.Context()
+	info, ok := FromContext(suite.ctx)
+	suite.True(ok)
+	suite.Equal("TestContext", info.Name)
+	suite.Nil(suite.ctx.Err())
+}
+
+func (suite *interruptedSuite) TestInterrupt() {
+	suite.ran = append(suite.ran, "TestInterrupt")
+	process, _ := os.FindProcess(os.Getpid())
+	if err := process.Signal(os.Interrupt); err != nil {
+		suite.Skip(err.Error())
+	}
+	select {
+	case <-suite.Context().Done():
+	case <-time.After(time.Second):
+		suite.Error("The context should be cancelled on SIGINT")
+	}
+}
+
+func (suite *interruptedSuite) TestNotStarted() {
+	suite.ran = append(suite.ran, "TestNotStarted")
+}
+
+func TestContext(t *testing.T) {
+	suite := new(contextSuite)
+	Run(t, suite)
+	if suite.ctx.Err() == nil {
+		t.Error("The context should be cancelled when the test finishes")
+	}
+
+	ft, interrupted := new(fakeT), new(interruptedSuite)
+	Run(ft, interrupted)
+	if strings.Join(interrupted.ran, ",") != "TestInterrupt" {
+		t.Errorf("No tests should start after SIGINT but ran %v\n", interrupted.ran)
+	}
+	if !ft.failed {
+		t.Error("An interrupted run should fail")
+	}
+
+	ft, suite = new(fakeT), new(contextSuite)
+	Run(ft, suite)
+	interruptOnce = new(sync.Once)
+	if len(suite.TestFuncs) != 0 || !ft.failed {
+		t.Error("The runs following an interrupted one should stop as well")
+	}
+}
+
+func (suite *logSuite) TestLog() {
+	suite.Logf("answer is %d", 42)
+	fmt.Println("printed on stdout")
+	log.Print("printed by the logger")
+	suite.True(true)
+}
+
+func TestLog(t *testing.T) {
+	*captureOutput = true
+	defer func() { *captureOutput = false }()
+	suite := new(logSuite)
+	Run(t, suite)
+	logs := strings.Join(suite.TestFuncs["TestLog"].Logs, "\n")
+	for _, expected := range []string{"prettytest_test.go", "answer is 42", "printed on stdout", "printed by the logger"} {
+		if !strings.Contains(logs, expected) {
+			t.Errorf("The log should contain %q but was %q\n", expected, logs)
+		}
+	}
+}
+
+func (suite *leakSuite) LeakCheck() string {
+	return "test"
+}
+
+func (suite *leakSuite) TestLeaking() {
+	suite.MustFail()
+	go func() { <-suite.stop }()
+	suite.True(true)
+}
+
+func (suite *leakSuite) TestStopped() {
+	done := make(chan bool)
+	go func() { <-done }()
+	suite.Cleanup(func() { close(done) })
+	suite.True(true)
+}
+
+func TestLeaks(t *testing.T) {
+	suite := &leakSuite{stop: make(chan bool)}
+	defer close(suite.stop)
+	Run(t, suite)
+	if len(ErrorLog) != 1 || !strings.Contains(ErrorLog[0].Assertion.ErrorMessage, "1 goroutine(s) leaked") {
+		t.Error("The leaked goroutine should be logged")
+	}
+	if status := suite.TestFuncs["TestStopped"].Status; status != STATUS_PASS {
+		t.Errorf("Goroutines stopped by a cleanup should not be reported but got status %d\n", status)
+	}
+}
+
+func (suite *benchmarkSuite) BeforeAll() { suite.beforeAll++ }
+func (suite *benchmarkSuite) Before()    { suite.before++ }
+
+func (suite *benchmarkSuite) BenchmarkJoin(b *B) {
+	for i := 0; i < b.N; i++ {
+		strings.Join([]string{"a", "b"}, ",")
+	}
+	suite.n = b.N
+	suite.Equal(1, suite.beforeAll)
+}
+
+func (suite *benchmarkSuite) BenchmarkSetup(b *B) {
+	time.Sleep(20 * time.Millisecond)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		strings.Join([]string{"a", "b"}, ",")
+	}
+}
+
+func (suite *benchmarkSuite) BenchmarkHelper(n int) int { return n }
+func (suite *benchmarkSuite) Benchmarked()              {}
+
+type benchmarkResults struct {
+	TDDFormatter
+	results map[string]testing.BenchmarkResult
+}
+
+func (formatter *benchmarkResults) PrintBenchmark(testFunc *TestFunc, result testing.BenchmarkResult) {
+	formatter.results[testFunc.Name] = result
+}
+
+func TestBenchmarks(t *testing.T) {
+	benchtime := flag.Lookup("test.benchtime")
+	previous := benchtime.Value.String()
+	benchtime.Value.Set("10x")
+	defer benchtime.Value.Set(previous)
+	suite := new(benchmarkSuite)
+	formatter := &benchmarkResults{results: make(map[string]testing.BenchmarkResult)}
+	result := testing.Benchmark(func(b *testing.B) { RunBenchmarksWithFormatter(b, formatter, suite) })
+	if suite.beforeAll != 1 || suite.before < 2 {
+		t.Errorf("BeforeAll should run once and Before for each invocation but ran %d and %d times\n", suite.beforeAll, suite.before)
+	}
+	if suite.n != 10 {
+		t.Errorf("The last invocation should run 10 times but ran %d times (%v)\n", suite.n, result)
+	}
+	if status := suite.TestFuncs["BenchmarkJoin"].Status; status != STATUS_PASS {
+		t.Errorf("BenchmarkJoin should pass but got status %d\n", status)
+	}
+	if setup := formatter.results["BenchmarkSetup"]; setup.N != 10 || setup.T >= 20*time.Millisecond {
+		t.Errorf("The time spent before ResetTimer should not be measured but got %v\n", setup)
+	}
+	if len(ErrorLog) != 0 {
+		t.Error("Helpers with the Benchmark prefix should not be taken for benchmarks")
+	}
+	Run(t, new(benchmarkSuite))
+}
+
+func (suite *propertySuite) TestHolds() {
+	suite.Property("reversing twice", func(xs []int) bool {
+		reversed := make([]int, 0, len(xs))
+		for i := len(xs) - 1; i >= 0; i-- {
+			reversed = append(reversed, xs[i])
+		}
+		for i, j := 0, len(reversed)-1; i < j; i, j = i+1, j-1 {
+			reversed[i], reversed[j] = reversed[j], reversed[i]
+		}
+		return fmt.Sprint(reversed) == fmt.Sprint(xs)
+	})
+}
+
+func (suite *propertySuite) TestShrinkInt() {
+	suite.MustFail()
+	suite.Property("small numbers", func(n int) bool { return n < 10 })
+}
+
+func (suite *propertySuite) TestLargeNumbers() {
+	suite.MustFail()
+	suite.Property("bounded numbers", func(n int, x float64) bool { return n <= 1000 && x <= 1000 })
+}
+
+func (suite *propertySuite) TestBoundaries() {
+	suite.MustFail()
+	suite.Property("no overflow", func(n int32, u uint8) bool { return n != math.MinInt32 && u != math.MaxUint8 })
+}
+
+func (tree *propertyTree) depth() int {
+	if tree == nil {
+		return 0
+	}
+	depth := tree.Left.depth()
+	if right := tree.Right.depth(); right > depth {
+		depth = right
+	}
+	for i := range tree.Children {
+		if child := tree.Children[i].depth(); child > depth {
+			depth = child
+		}
+	}
+	for _, label := range tree.Labels {
+		if child := label.depth(); child > depth {
+			depth = child
+		}
+	}
+	return depth + 1
+}
+
+func (suite *propertySuite) TestRecursive() {
+	suite.Property("finite trees", func(tree *propertyTree) bool { return tree.depth() <= 2*propertyMaxSize })
+}
+
+func (suite *propertySuite) TestShrinkString() {
+	suite.MustFail()
+	suite.Property("no a", func(s string) bool { return !strings.Contains(s, "a") })
+}
+
+func (suite *propertySuite) TestInvalid() {
+	suite.MustFail()
+	suite.Property("not a predicate", func(n int) int { return n })
+}
+
+func TestProperty(t *testing.T) {
+	*propertySeed = 1
+	defer func() { *propertySeed = 0 }()
+	Run(t, new(propertySuite))
+	expected := map[string]string{
+		"TestInvalid":      "must be a func returning bool",
+		"TestShrinkInt":    "with seed 1 (rerun with -pt.property-seed=1)\nCounterexample: 10",
+		"TestShrinkString": "Counterexample: \"a\"",
+		"TestLargeNumbers": "Counterexample: ",
+		"TestBoundaries":   "Counterexample: ",
+	}
+	if len(ErrorLog) != len(expected) {
+		t.Fatalf("Expected %d errors but got %d\n", len(expected), len(ErrorLog))
+	}
+	for _, error := range ErrorLog {
+		if message := error.Assertion.ErrorMessage; !strings.Contains(message, expected[error.TestFunc.Name]) {
+			t.Errorf("%s should report %q but reported %q\n", error.TestFunc.Name, expected[error.TestFunc.Name], message)
+		}
+	}
+}
+
+func (suite *fuzzSuite) BeforeAll() { suite.beforeAll++ }
+func (suite *fuzzSuite) Before()    { suite.inputs++ }
+
+func (suite *fuzzSuite) FuzzQuote(text string, n int) {
+	unquoted, err := strconv.Unquote(strconv.Quote(text))
+	suite.Nil(err)
+	suite.Equal(text, unquoted)
+	suite.Equal(1, suite.beforeAll)
+}
+
+func FuzzRunFuzz(f *testing.F) {
+	f.Add("", 0)
+	f.Add("prettytest", 42)
+	f.Add("\x00\n\"", -1)
+	suite := new(fuzzSuite)
+	RunFuzz(f, suite, "FuzzQuote")
+	f.Cleanup(func() {
+		if suite.inputs < 3 {
+			f.Errorf("Before should run for each input but ran %d times", suite.inputs)
+		}
+	})
+}
+
+func (suite *httpSuite) TestHTTP() {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"a": {"b": 3}}`)
+	})
+	suite.HTTP(handler).GET("/x").Expect().Status(http.StatusOK).JSON().Path("a.b").Equal(3)
+}
+
+func TestHTTP(t *testing.T) {
+	suite := new(httpSuite)
+	Run(t, suite)
+	assertions := suite.TestFuncs["TestHTTP"].Assertions
+	if len(assertions) != 5 {
+		t.Fatalf("Each step should record an assertion but got %d assertions\n", len(assertions))
+	}
+	if filepath.Base(assertions[4].Filename) != "prettytest_test.go" {
+		t.Errorf("Steps should be recorded at the caller's line but were recorded in %s\n", assertions[4].Filename)
+	}
+}
+
+type fixtureUser struct {
+	Name string
+	Tags []string
+}
+
+func (suite *fixtureSuite) TestJSON() {
+	var user fixtureUser
+	suite.Fixture("user.json", &user)
+	suite.Equal("andrea", user.Name)
+	suite.Equal(2, len(user.Tags))
+}
+
+func (suite *fixtureSuite) TestText() {
+	var greeting string
+	suite.Fixture("greeting.txt", &greeting)
+	suite.Equal("Hello, prettytest!\n", greeting)
+}
+
+func (suite *fixtureSuite) TestBytes() {
+	var data []byte
+	suite.Fixture("greeting.txt", &data)
+	data[0] = 'X'
+	var greeting string
+	suite.Fixture("greeting.txt", &greeting)
+	suite.Equal("Hello, prettytest!\n", greeting, "Changing the bytes of a fixture should not change the cached fixture")
+}
+
+func (suite *fixtureSuite) TestTemplate() {
+	var user fixtureUser
+	suite.Fixture("user.json.tmpl", &user, map[string]string{"Name": "remo"})
+	suite.Equal("remo", user.Name)
+}
+
+func (suite *fixtureSuite) TestMissing() {
+	var user fixtureUser
+	suite.MustFail()
+	suite.Fixture("missing.json", &user)
+}
+
+func (suite *fixtureSuite) TestMalformed() {
+	var user fixtureUser
+	suite.MustFail()
+	suite.Fixture("malformed.json", &user)
+}
+
+func TestFixture(t *testing.T) {
+	Run(t, new(fixtureSuite))
+	expected := map[string]string{
+		"TestMissing":   "Fixture testdata/fixtureSuite/missing.json not found",
+		"TestMalformed": "Fixture testdata/fixtureSuite/malformed.json is malformed",
+	}
+	if len(ErrorLog) != len(expected) {
+		t.Fatalf("Expected %d errors but got %d\n", len(expected), len(ErrorLog))
+	}
+	for _, error := range ErrorLog {
+		if message := error.Assertion.ErrorMessage; !strings.HasPrefix(message, expected[error.TestFunc.Name]) {
+			t.Errorf("%s should report %q but reported %q\n", error.TestFunc.Name, expected[error.TestFunc.Name], message)
+		}
+	}
+}
+
+func (suite *injectedSuite) BeforeAll() {
+	suite.seen = suite.Dir != "" && suite.Server != nil && suite.Counter != nil
+}
+
+func (suite *injectedSuite) ServeHello(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprint(w, "hello")
+}
+
+func (suite *injectedSuite) TestFields() {
+	suite.True(suite.seen)
+	suite.Path(suite.Dir)
+	response, err := http.Get(suite.Server.URL)
+	suite.Nil(err)
+	body, _ := ioutil.ReadAll(response.Body)
+	response.Body.Close()
+	suite.Equal("hello", string(body))
+	suite.Equal("injected", suite.User.Name)
+	suite.Nil(suite.Ignored)
+}
+
+func (suite *misinjectedSuite) TestNotRun() {
+	suite.True(suite.dir == "" && suite.Server == "" && suite.Other == 0)
+}
+
+func TestInjection(t *testing.T) {
+	RegisterProvider(func() (*injectedCounter, func()) {
+		counter := new(injectedCounter)
+		return counter, func() { counter.closed = true }
+	})
+	suite := new(injectedSuite)
+	Run(t, suite)
+	if _, err := os.Stat(suite.Dir); !os.IsNotExist(err) {
+		t.Errorf("The temporary directory %s should be removed after AfterAll\n", suite.Dir)
+	}
+	if _, err := http.Get(suite.Server.URL); err == nil {
+		t.Error("The server should be closed after AfterAll")
+	}
+	if !suite.Counter.closed {
+		t.Error("The teardown of the provider should be called after AfterAll")
+	}
+
+	ft := new(fakeT)
+	Run(ft, new(misinjectedSuite))
+	if !ft.failed || len(ErrorLog) != 3 {
+		t.Errorf("Misconfigured fields should be reported as failures but got %d errors\n", len(ErrorLog))
+	}
+}
+
+func (s *sliceStack) Push(n int) { *s = append(*s, n) }
+func (s *sliceStack) Len() int   { return len(*s) }
+func (s *listStack) Push(n int)  { s.n++ }
+func (s *listStack) Len() int    { return s.n }
+
+func (e *stackExamples) TestEmpty() {
+	e.Equal(0, e.Stack.Len())
+}
+
+func (e *stackExamples) TestPush() {
+	e.Stack.Push(1)
+	e.Stack.Push(2)
+	e.Equal(2, e.Stack.Len())
+}
+
+func (suite *sliceStackSuite) Before() {
+	suite.Examples.Stack = new(sliceStack)
+}
+
+func (suite *listStackSuite) Before() {
+	suite.Examples.Stack = new(listStack)
+}
+
+func (suite *hiddenSharedSuite) TestNothing() {}
+
+func TestSharedExamples(t *testing.T) {
+	sliceSuite, listSuite := new(sliceStackSuite), new(listStackSuite)
+	Run(t, sliceSuite, listSuite)
+	for _, s := range []*Suite{&sliceSuite.Suite, &listSuite.Suite} {
+		for _, name := range []string{"Examples.TestEmpty", "Examples.TestPush"} {
+			testFunc, ok := s.TestFuncs[name]
+			if !ok || testFunc.Status != STATUS_PASS || len(testFunc.Assertions) != 1 {
+				t.Errorf("%s should be run and pass under %s\n", name, s.Name)
+			}
+		}
+	}
+
+	ft := new(fakeT)
+	Run(ft, new(hiddenSharedSuite))
+	if !ft.failed || len(ErrorLog) != 1 || !strings.Contains(ErrorLog[0].Assertion.ErrorMessage, "must be exported") {
+		t.Error("Unexported shared examples fields should be reported as failures")
+	}
+}
+
+func (suite *specSuite) SpecStack() {
+	suite.Describe("Stack", func() {
+		var stack []int
+		suite.BeforeEach(func() {
+			stack = nil
+			suite.calls = append(suite.calls, "outer before")
+		})
+		suite.AfterEach(func() { suite.calls = append(suite.calls, "outer after") })
+		suite.It("is empty", func() {
+			suite.Equal(0, len(stack))
+		})
+		suite.Describe("after a push", func() {
+			suite.BeforeEach(func() {
+				stack = append(stack, 1)
+				suite.calls = append(suite.calls, "inner before")
+			})
+			suite.AfterEach(func() { suite.calls = append(suite.calls, "inner after") })
+			suite.It("has one element", func() {
+				suite.calls = append(suite.calls, "spec")
+				suite.Equal(1, len(stack))
+			})
+			suite.XIt("can be popped", func() {})
+		})
+	})
+}
+
+func (suite *specSuite) Spec_queue() {
+	suite.Describe("Queue", func() {
+		suite.With("no elements", func() {
+			suite.It("is empty", func() { suite.True(true) })
+		})
+	})
+}
+
+func (suite *specSuite) SpecialCase(n int) int { return n }
+func (suite *specSuite) Specimen()             { suite.calls = append(suite.calls, "specimen") }
+
+func (suite *specSuite) TestDescribeOutsideSpecs() {
+	suite.MustFail()
+	suite.Describe("Nothing", func() {})
+}
+
+func (suite *focusedSpecSuite) SpecFocus() {
+	suite.Describe("Focus", func() {
+		suite.It("is not run", func() { suite.run = append(suite.run, "unfocused") })
+		suite.FIt("is run", func() {
+			suite.run = append(suite.run, "focused")
+			suite.True(true)
+		})
+	})
+}
+
+func (suite *focusedSpecSuite) SpecFocusedGroup() {
+	suite.FDescribe("Focused group", func() {
+		suite.It("is run", func() {
+			suite.run = append(suite.run, "group")
+			suite.True(true)
+		})
+	})
+}
+
+func (suite *focusedSuite) FTestFocused() {
+	suite.run = append(suite.run, "method")
+	suite.True(true)
+}
+
+func (suite *focusedSuite) TestNotFocused() {
+	suite.run = append(suite.run, "unfocused")
+}
+
+func TestFocus(t *testing.T) {
+	methods, specs, other := new(focusedSuite), new(focusedSpecSuite), new(testSuite)
+	plans := []*suitePlan{}
+	for _, s := range []tCatcher{methods, specs, other} {
+		prepare(t, s)
+		plans = append(plans, newSuitePlan(s, new(TDDFormatter)))
+	}
+	unfocused := len(plans[2].methods) + 2
+	if focused, skipped := applyFocus(plans); !focused || skipped != unfocused {
+		t.Errorf("Expected the tests which are not focused to be skipped but %d were\n", skipped)
+	}
+
+	Run(t, methods, specs, other)
+	if strings.Join(methods.run, ", ") != "method" || strings.Join(specs.run, ", ") != "focused, group" {
+		t.Errorf("Only focused tests should be run but %v and %v were\n", methods.run, specs.run)
+	}
+	if len(other.TestFuncs) != 0 {
+		t.Error("Suites without focused tests should not be run")
+	}
+
+	*noFocus = true
+	defer func() { *noFocus = false }()
+	ft := new(fakeT)
+	methods = new(focusedSuite)
+	Run(ft, methods)
+	if !ft.failed || len(ErrorLog) != 1 || len(methods.run) != 2 {
+		t.Error("Focused tests should fail the run and not be focused with -pt.no-focus")
+	}
+}
+
+func TestStatusColumn(t *testing.T) {
+	output := new(TestFunc)
+	c := startCapture(output)
+	formatter := new(TDDFormatter)
+	for _, name := range []string{"TestShort", "Stack/after a push/has one element"} {
+		testFunc := &TestFunc{Name: name, Status: STATUS_PASS, Assertions: []*Assertion{{Passed: true}}}
+		formatter.PrintStatus(testFunc)
+	}
+	c.stop()
+	if len(output.Logs) != 2 || !strings.Contains(output.Logs[0], "TestShort                     (1 assertion(s))") || !strings.Contains(output.Logs[1], "has one element (1 assertion(s))") {
+		t.Errorf("Names should be separated from the number of assertions but got %q\n", output.Logs)
+	}
+}
+
+func TestSpecs(t *testing.T) {
+	suite := new(specSuite)
+	Run(t, suite)
+	expected := map[string]int{
+		"Stack/is empty":                     STATUS_PASS,
+		"Stack/after a push/has one element": STATUS_PASS,
+		"Stack/after a push/can be popped":   STATUS_PENDING,
+		"TestDescribeOutsideSpecs":           STATUS_MUST_FAIL,
+	}
+	for name, status := range expected {
+		if testFunc, ok := suite.TestFuncs[name]; !ok || testFunc.Status != status {
+			t.Errorf("%s should have status %d\n", name, status)
+		}
+	}
+	if path := suite.TestFuncs["Stack/after a push/has one element"].Path; strings.Join(path, "/") != "Stack/after a push" {
+		t.Errorf("Expected the path of the spec to be its groups but got %v\n", path)
+	}
+	calls := "outer before, outer after, outer before, inner before, spec, inner after, outer after"
+	if strings.Join(suite.calls, ", ") != calls {
+		t.Errorf("Expected calls %q but got %q\n", calls, strings.Join(suite.calls, ", "))
+	}
+	RunWithFormatter(t, &BDDFormatter{Description: "Specs"}, new(specSuite))
+}
+
+func (suite *scenarioSuite) TestPassing() {
+	var items []string
+	suite.Given("an empty cart", func() { items = nil })
+	suite.When("an item is added", func() { items = append(items, "book") })
+	suite.Then("the cart has one item", func() { suite.Equal(1, len(items)) })
+	suite.And("it is the added item", func() { suite.Equal("book", items[0]) })
+}
+
+func (suite *scenarioSuite) TestFailing() {
+	suite.MustFail()
+	suite.Given("a step", func() { suite.ran = append(suite.ran, "given") })
+	suite.When("a step fails", func() { suite.True(false) })
+	suite.Then("the following steps are not run", func() { suite.ran = append(suite.ran, "then") })
+}
+
+func (suite *scenarioSuite) TestPending() {
+	suite.Given("a pending step", nil)
+	suite.Then("the following steps are not run", func() { suite.ran = append(suite.ran, "pending") })
+}
+
+func TestScenarios(t *testing.T) {
+	suite := new(scenarioSuite)
+	Run(t, suite)
+	expected := map[string][]int{
+		"TestPassing": {STATUS_PASS, STATUS_PASS, STATUS_PASS, STATUS_PASS},
+		"TestFailing": {STATUS_PASS, STATUS_FAIL, STATUS_SKIPPED},
+		"TestPending": {STATUS_PENDING, STATUS_SKIPPED},
+	}
+	for name, statuses := range expected {
+		steps := suite.TestFuncs[name].Steps
+		if len(steps) != len(statuses) {
+			t.Errorf("%s should have %d steps but has %d\n", name, len(statuses), len(steps))
+			continue
+		}
+		for i, step := range steps {
+			if step.Status != statuses[i] {
+				t.Errorf("Step %q of %s should have status %d but has %d\n", step.Text, name, statuses[i], step.Status)
+			}
+		}
+	}
+	if strings.Join(suite.ran, ", ") != "given" {
+		t.Errorf("Steps following a failed or pending step should not run but %v did\n", suite.ran)
+	}
+	if status := suite.TestFuncs["TestPending"].Status; status != STATUS_PENDING {
+		t.Errorf("A scenario with a pending step should be pending but has status %d\n", status)
+	}
+}
+
 func (suite *bddFormatterSuite) Should_use_green_on_passing_examples() {
 	suite.True(true)
 }
@@ -157,6 +1443,15 @@ func (suite *bddFormatterSuite) Should_use_yellow_on_pending_examples() {
 
 func (suite *bddFormatterSuite) Should_use_yellow_on_examples_with_no_assertions() {}
 
+func (suite *bddFormatterSuite) Should_use_yellow_on_skipped_examples() {
+	suite.Skip("Showing the reason")
+}
+
+func (suite *bddFormatterSuite) Should_print_the_steps_of_scenarios() {
+	suite.Given("a scenario", func() {})
+	suite.Then("its steps are printed", func() { suite.True(true) })
+}
+
 func TestBDDStyleSpecs(t *testing.T) {
 	RunWithFormatter(
 		t,
@@ -0,0 +1,381 @@
+package prettytest
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing/quick"
+	"time"
+)
+
+const (
+	// propertyMaxSize bounds the length of the generated slices,
+	// maps and strings. Numbers are drawn from the whole range of
+	// their type.
+	propertyMaxSize = 100
+
+	// propertyMaxShrinks bounds the number of calls made while
+	// shrinking a counterexample.
+	propertyMaxShrinks = 1000
+)
+
+// Property asserts that fn returns true for randomly generated
+// arguments. fn must be a function returning a bool; its arguments
+// are generated according to their types, using the Generate method
+// of the types implementing testing/quick.Generator. A call that
+// panics counts as a failure.
+//
+// The number of calls and the seed of the generator are set by
+// -pt.property-runs and -pt.property-seed. When fn fails, its
+// arguments are shrunk to a minimal counterexample which is reported
+// together with the seed.
+func (s *Suite) Property(name string, fn interface{}, messages ...string) *Assertion {
+	assertion := s.setup("", messages)
+	f := reflect.ValueOf(fn)
+	if f.Kind() != reflect.Func || f.Type().NumOut() != 1 || f.Type().Out(0).Kind() != reflect.Bool {
+		assertion.ErrorMessage = fmt.Sprintf("Property %s must be a func returning bool, got %T", name, fn)
+		assertion.fail()
+		return assertion
+	}
+
+	seed := *propertySeed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	r := rand.New(rand.NewSource(seed))
+	runs := *propertyRuns
+	for i := 0; i < runs; i++ {
+		args, err := generateArgs(f.Type(), r, 1+i*propertyMaxSize/runs)
+		if err != nil {
+			assertion.ErrorMessage = fmt.Sprintf("Property %s: %s", name, err)
+			assertion.fail()
+			return assertion
+		}
+		if ok, _ := checkProperty(f, args); !ok {
+			args = shrinkArgs(f, args)
+			_, panicked := checkProperty(f, args)
+			message := fmt.Sprintf("Property %s failed after %d run(s) with seed %d (rerun with -pt.property-seed=%d)\nCounterexample: %s",
+				name, i+1, seed, seed, formatArgs(args))
+			if panicked != nil {
+				message += fmt.Sprintf("\nPanic: %v", panicked)
+			}
+			if len(messages) > 0 {
+				message = assertion.ErrorMessage + "\n" + message
+			}
+			assertion.ErrorMessage = message
+			assertion.fail()
+			return assertion
+		}
+	}
+	return assertion
+}
+
+// checkProperty calls f with args and reports whether it returned
+// true. A panic is recovered and returned, except for Skip.
+func checkProperty(f reflect.Value, args []reflect.Value) (ok bool, panicked interface{}) {
+	defer func() {
+		if value := recover(); value != nil {
+			if _, skip := value.(skipSignal); skip {
+				panic(value)
+			}
+			ok, panicked = false, value
+		}
+	}()
+	return f.Call(args)[0].Bool(), nil
+}
+
+func formatArgs(args []reflect.Value) string {
+	result := make([]string, len(args))
+	for i, arg := range args {
+		result[i] = fmt.Sprintf("%#v", arg.Interface())
+	}
+	return strings.Join(result, ", ")
+}
+
+func generateArgs(fnType reflect.Type, r *rand.Rand, size int) ([]reflect.Value, error) {
+	args := make([]reflect.Value, fnType.NumIn())
+	for i := range args {
+		arg, err := generate(fnType.In(i), r, size)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = arg
+	}
+	return args, nil
+}
+
+var generatorType = reflect.TypeOf((*quick.Generator)(nil)).Elem()
+
+// generate returns a random value of type t. size bounds the length
+// of the collections. The elements of slices and maps and the values
+// pointed to are generated with a smaller size, so that values of
+// recursive types stay finite: at size 0, pointers are nil and slices
+// and maps empty.
+func generate(t reflect.Type, r *rand.Rand, size int) (reflect.Value, error) {
+	if t.Implements(generatorType) {
+		return reflect.Zero(t).Interface().(quick.Generator).Generate(r, size), nil
+	}
+	v := reflect.New(t).Elem()
+	switch t.Kind() {
+	case reflect.Bool:
+		v.SetBool(r.Intn(2) == 1)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(randomInt(r, t.Bits()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v.SetUint(randomUint(r, t.Bits()))
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(randomFloat(r, t.Bits()))
+	case reflect.Complex64, reflect.Complex128:
+		v.SetComplex(complex(randomFloat(r, t.Bits()/2), randomFloat(r, t.Bits()/2)))
+	case reflect.String:
+		runes := make([]rune, r.Intn(size+1))
+		for i := range runes {
+			if r.Intn(10) == 0 {
+				runes[i] = rune(0xa0 + r.Intn(0x2000))
+			} else {
+				runes[i] = rune(' ' + r.Intn('~'-' '+1))
+			}
+		}
+		v.SetString(string(runes))
+	case reflect.Slice:
+		n := r.Intn(size + 1)
+		v.Set(reflect.MakeSlice(t, n, n))
+		for i := 0; i < n; i++ {
+			elem, err := generate(t.Elem(), r, (size-n)/2)
+			if err != nil {
+				return v, err
+			}
+			v.Index(i).Set(elem)
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			elem, err := generate(t.Elem(), r, size)
+			if err != nil {
+				return v, err
+			}
+			v.Index(i).Set(elem)
+		}
+	case reflect.Map:
+		v.Set(reflect.MakeMap(t))
+		n := r.Intn(size + 1)
+		for i := n; i > 0; i-- {
+			key, err := generate(t.Key(), r, (size-n)/2)
+			if err != nil {
+				return v, err
+			}
+			elem, err := generate(t.Elem(), r, (size-n)/2)
+			if err != nil {
+				return v, err
+			}
+			v.SetMapIndex(key, elem)
+		}
+	case reflect.Ptr:
+		if size > 0 && r.Intn(10) > 0 {
+			elem, err := generate(t.Elem(), r, size/2)
+			if err != nil {
+				return v, err
+			}
+			v.Set(reflect.New(t.Elem()))
+			v.Elem().Set(elem)
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !v.Field(i).CanSet() {
+				continue
+			}
+			field, err := generate(t.Field(i).Type, r, size)
+			if err != nil {
+				return v, err
+			}
+			v.Field(i).Set(field)
+		}
+	default:
+		return v, fmt.Errorf("cannot generate values of type %s", t)
+	}
+	return v, nil
+}
+
+// randomInt returns a random integer of the given number of bits.
+// One in five is a boundary value, like testing/quick mixes in; the
+// others have a random number of significant bits, so that small and
+// large magnitudes are equally likely.
+func randomInt(r *rand.Rand, bits int) int64 {
+	if r.Intn(5) == 0 {
+		max := int64(1)<<uint(bits-1) - 1
+		return []int64{0, 1, -1, max, -max - 1}[r.Intn(5)]
+	}
+	return int64(r.Uint64()) >> uint(64-bits+r.Intn(bits))
+}
+
+// randomUint returns a random unsigned integer of the given number of
+// bits, drawn like those of randomInt.
+func randomUint(r *rand.Rand, bits int) uint64 {
+	if r.Intn(5) == 0 {
+		return []uint64{0, 1, 1<<uint(bits-1)<<1 - 1}[r.Intn(3)]
+	}
+	return r.Uint64() >> uint(64-bits+r.Intn(bits))
+}
+
+// randomFloat returns a random finite float of the given number of
+// bits, with an exponent drawn from the whole range of the type.
+func randomFloat(r *rand.Rand, bits int) float64 {
+	max, maxExp := math.MaxFloat64, 1024
+	if bits == 32 {
+		max, maxExp = math.MaxFloat32, 128
+	}
+	if r.Intn(5) == 0 {
+		return []float64{0, 1, -1, max, -max}[r.Intn(5)]
+	}
+	return math.Ldexp(r.Float64()*2-1, r.Intn(2*maxExp)-maxExp)
+}
+
+// shrinkArgs looks for smaller arguments for which f still fails,
+// replacing one argument at a time with the first smaller candidate
+// that fails until no candidate does.
+func shrinkArgs(f reflect.Value, args []reflect.Value) []reflect.Value {
+	calls := 0
+	for shrunk := true; shrunk && calls < propertyMaxShrinks; {
+		shrunk = false
+		for i := 0; i < len(args) && !shrunk; i++ {
+			for _, candidate := range shrink(args[i]) {
+				if calls++; calls > propertyMaxShrinks {
+					break
+				}
+				next := append([]reflect.Value(nil), args...)
+				next[i] = candidate
+				if ok, _ := checkProperty(f, next); !ok {
+					args, shrunk = next, true
+					break
+				}
+			}
+		}
+	}
+	return args
+}
+
+// shrink returns values smaller than v, simplest first.
+func shrink(v reflect.Value) (result []reflect.Value) {
+	t := v.Type()
+	value := func(set func(reflect.Value)) {
+		candidate := reflect.New(t).Elem()
+		set(candidate)
+		result = append(result, candidate)
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			value(func(c reflect.Value) { c.SetBool(false) })
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if n := v.Int(); n != 0 {
+			value(func(c reflect.Value) {})
+			if n < 0 && !v.OverflowInt(-n) {
+				value(func(c reflect.Value) { c.SetInt(-n) })
+			}
+			value(func(c reflect.Value) { c.SetInt(n / 2) })
+			if n > 0 {
+				value(func(c reflect.Value) { c.SetInt(n - 1) })
+			} else {
+				value(func(c reflect.Value) { c.SetInt(n + 1) })
+			}
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if n := v.Uint(); n != 0 {
+			value(func(c reflect.Value) {})
+			value(func(c reflect.Value) { c.SetUint(n / 2) })
+			value(func(c reflect.Value) { c.SetUint(n - 1) })
+		}
+	case reflect.Float32, reflect.Float64:
+		if x := v.Float(); x != 0 {
+			value(func(c reflect.Value) {})
+			if x < 0 {
+				value(func(c reflect.Value) { c.SetFloat(-x) })
+			}
+			if float64(int64(x)) != x {
+				value(func(c reflect.Value) { c.SetFloat(float64(int64(x))) })
+			}
+			value(func(c reflect.Value) { c.SetFloat(x / 2) })
+		}
+	case reflect.String:
+		runes := []rune(v.String())
+		if len(runes) > 0 {
+			value(func(c reflect.Value) {})
+			value(func(c reflect.Value) { c.SetString(string(runes[:len(runes)/2])) })
+			value(func(c reflect.Value) { c.SetString(string(runes[len(runes)/2:])) })
+			for i := range runes {
+				shorter := append(append([]rune(nil), runes[:i]...), runes[i+1:]...)
+				value(func(c reflect.Value) { c.SetString(string(shorter)) })
+			}
+		}
+	case reflect.Slice:
+		n := v.Len()
+		if n > 0 {
+			value(func(c reflect.Value) { c.Set(reflect.MakeSlice(t, 0, 0)) })
+			value(func(c reflect.Value) { c.Set(v.Slice(0, n/2)) })
+			value(func(c reflect.Value) { c.Set(v.Slice(n/2, n)) })
+			for i := 0; i < n; i++ {
+				value(func(c reflect.Value) {
+					c.Set(reflect.AppendSlice(reflect.MakeSlice(t, 0, n-1), v.Slice(0, i)))
+					c.Set(reflect.AppendSlice(c, v.Slice(i+1, n)))
+				})
+			}
+			for i := 0; i < n; i++ {
+				for _, elem := range shrink(v.Index(i)) {
+					value(func(c reflect.Value) {
+						c.Set(reflect.AppendSlice(reflect.MakeSlice(t, 0, n), v))
+						c.Index(i).Set(elem)
+					})
+				}
+			}
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			for _, elem := range shrink(v.Index(i)) {
+				value(func(c reflect.Value) {
+					c.Set(v)
+					c.Index(i).Set(elem)
+				})
+			}
+		}
+	case reflect.Map:
+		if v.Len() > 0 {
+			value(func(c reflect.Value) { c.Set(reflect.MakeMap(t)) })
+			keys := v.MapKeys()
+			for i := range keys {
+				value(func(c reflect.Value) {
+					c.Set(reflect.MakeMap(t))
+					for j, key := range keys {
+						if j != i {
+							c.SetMapIndex(key, v.MapIndex(key))
+						}
+					}
+				})
+			}
+		}
+	case reflect.Ptr:
+		if !v.IsNil() {
+			value(func(c reflect.Value) {})
+			for _, elem := range shrink(v.Elem()) {
+				value(func(c reflect.Value) {
+					c.Set(reflect.New(t.Elem()))
+					c.Elem().Set(elem)
+				})
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if !v.Field(i).CanSet() {
+				continue
+			}
+			for _, field := range shrink(v.Field(i)) {
+				value(func(c reflect.Value) {
+					c.Set(v)
+					c.Field(i).Set(field)
+				})
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,316 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/importer"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"log"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	mockImportPath       = "github.com/remogatto/prettytest/mock"
+	prettytestImportPath = "github.com/remogatto/prettytest"
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "ptmock generates prettytest mocks for the interfaces of a package\n\n")
+	fmt.Fprintf(os.Stderr, "Usage:\n\n")
+	fmt.Fprintf(os.Stderr, "\tptmock [options] package [interface ...]\n\n")
+	fmt.Fprintf(os.Stderr, "The package is an import path or a relative directory (e.g. ./store).\n")
+	fmt.Fprintf(os.Stderr, "Without interface names, a mock is generated for every exported interface.\n\n")
+	fmt.Fprintf(os.Stderr, "Options are:\n\n")
+	flag.PrintDefaults()
+}
+
+// generator writes the mocks of the interfaces of pkg to a file of
+// package name, at import path path.
+type generator struct {
+	pkg     *types.Package
+	name    string
+	path    string
+	imports map[string]string
+	buf     bytes.Buffer
+}
+
+// qualifier returns the name used to refer to p in the generated
+// code, recording the imports it needs.
+func (g *generator) qualifier(p *types.Package) string {
+	if p.Path() == g.path {
+		return ""
+	}
+	g.imports[p.Path()] = p.Name()
+	return p.Name()
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buf, format, args...)
+}
+
+// interfaces returns the named interfaces to mock, all the exported
+// ones if names is empty.
+func (g *generator) interfaces(names []string) ([]*types.TypeName, error) {
+	if len(names) == 0 {
+		for _, name := range g.pkg.Scope().Names() {
+			if obj, ok := g.pkg.Scope().Lookup(name).(*types.TypeName); ok && obj.Exported() && types.IsInterface(obj.Type()) {
+				names = append(names, name)
+			}
+		}
+	}
+	var result []*types.TypeName
+	for _, name := range names {
+		obj, ok := g.pkg.Scope().Lookup(name).(*types.TypeName)
+		if !ok || !types.IsInterface(obj.Type()) {
+			return nil, fmt.Errorf("%s is not an interface of %s", name, g.pkg.Path())
+		}
+		if named, ok := obj.Type().(*types.Named); ok && named.TypeParams().Len() > 0 {
+			return nil, fmt.Errorf("%s is a generic interface, which is not supported", name)
+		}
+		result = append(result, obj)
+	}
+	return result, nil
+}
+
+// reserved are the identifiers used by the generated methods, which
+// parameters can't be named after.
+var reserved = map[string]bool{"m": true, "args": true, "r": true, "mock": true, "prettytest": true}
+
+// names are the identifiers in use in a generated method.
+type names map[string]bool
+
+// identifier matches the identifiers of a type, e.g. io and Reader in
+// io.Reader.
+var identifier = regexp.MustCompile(`[\pL_][\pL\pN_]*`)
+
+// newNames returns the identifiers the parameters of a method can't
+// be named after: the reserved ones and those referred to by the
+// result types, which the body of the method converts to.
+func newNames(results []string) names {
+	used := make(names)
+	for name := range reserved {
+		used[name] = true
+	}
+	for _, result := range results {
+		for _, name := range identifier.FindAllString(result, -1) {
+			used[name] = true
+		}
+	}
+	return used
+}
+
+// fresh returns base, or base followed by a number, whichever is the
+// first unused name.
+func (used names) fresh(base string) string {
+	name := base
+	for i := 1; used[name]; i++ {
+		name = fmt.Sprintf("%s_%d", base, i)
+	}
+	used[name] = true
+	return name
+}
+
+// params returns the names and the types of the parameters of sig.
+// Unnamed parameters, and those clashing with used, are renamed.
+func (g *generator) params(sig *types.Signature, used names) (paramNames, typeNames []string) {
+	for i := 0; i < sig.Params().Len(); i++ {
+		typ := types.TypeString(sig.Params().At(i).Type(), g.qualifier)
+		if sig.Variadic() && i == sig.Params().Len()-1 {
+			typ = "..." + strings.TrimPrefix(typ, "[]")
+		}
+		typeNames = append(typeNames, typ)
+	}
+	clashing := make(map[int]bool)
+	for i := 0; i < sig.Params().Len(); i++ {
+		name := sig.Params().At(i).Name()
+		clashing[i] = name == "" || name == "_" || used[name]
+		if !clashing[i] {
+			used[name] = true
+		}
+	}
+	for i := 0; i < sig.Params().Len(); i++ {
+		name := sig.Params().At(i).Name()
+		if clashing[i] {
+			name = used.fresh(fmt.Sprintf("a%d", i))
+		}
+		paramNames = append(paramNames, name)
+	}
+	return paramNames, typeNames
+}
+
+// checkNames returns an error if the methods generated for iface
+// would clash with each other or with the embedded mock.Mock field.
+// Methods named after those of mock.Mock, like On or Called, only
+// shadow them, since the generated code calls them through the field.
+func checkNames(obj *types.TypeName, iface *types.Interface) error {
+	generated := map[string]string{"Mock": ""}
+	for i := 0; i < iface.NumMethods(); i++ {
+		name := iface.Method(i).Name()
+		for _, generatedName := range []string{name, "On" + name, "Assert" + name + "Called"} {
+			other, ok := generated[generatedName]
+			switch {
+			case ok && other == "":
+				return fmt.Errorf("%s.%s clashes with the mock.Mock field of the mock", obj.Name(), name)
+			case ok:
+				return fmt.Errorf("%s.%s and %s.%s both need a method %s in the mock", obj.Name(), other, obj.Name(), name, generatedName)
+			}
+			generated[generatedName] = name
+		}
+	}
+	return nil
+}
+
+func (g *generator) generateMock(obj *types.TypeName) error {
+	iface := obj.Type().Underlying().(*types.Interface)
+	if err := checkNames(obj, iface); err != nil {
+		return err
+	}
+	mockName := "Mock" + obj.Name()
+	g.printf("// %s is a mock implementation of %s.\n", mockName, types.TypeString(obj.Type(), g.qualifier))
+	g.printf("type %s struct {\n\tmock.Mock\n}\n\n", mockName)
+
+	for i := 0; i < iface.NumMethods(); i++ {
+		method := iface.Method(i)
+		sig := method.Type().(*types.Signature)
+		var results []string
+		for i := 0; i < sig.Results().Len(); i++ {
+			results = append(results, types.TypeString(sig.Results().At(i).Type(), g.qualifier))
+		}
+		used := newNames(results)
+		names, paramTypes := g.params(sig, used)
+		params := make([]string, len(names))
+		matchers := make([]string, len(names))
+		for i := range names {
+			params[i] = names[i] + " " + paramTypes[i]
+			matchers[i] = names[i] + " interface{}"
+		}
+		resultList := strings.Join(results, ", ")
+		if len(results) > 1 {
+			resultList = "(" + resultList + ")"
+		}
+		args := ""
+		if len(names) > 0 {
+			args = ", " + strings.Join(names, ", ")
+		}
+
+		g.printf("func (m *%s) %s(%s) %s {\n", mockName, method.Name(), strings.Join(params, ", "), resultList)
+		if len(results) == 0 {
+			g.printf("\tm.Mock.MethodCalled(%q%s)\n", method.Name(), args)
+		} else {
+			g.printf("\targs := m.Mock.MethodCalled(%q%s)\n", method.Name(), args)
+			values := make([]string, len(results))
+			for i, result := range results {
+				values[i] = used.fresh(fmt.Sprintf("r%d", i))
+				g.printf("\t%s, _ := args.Get(%d).(%s)\n", values[i], i, result)
+			}
+			g.printf("\treturn %s\n", strings.Join(values, ", "))
+		}
+		g.printf("}\n\n")
+
+		g.printf("// On%s declares an expected call to %s. Arguments may be\n// values or mock matchers.\n", method.Name(), method.Name())
+		g.printf("func (m *%s) On%s(%s) *mock.Call {\n", mockName, method.Name(), strings.Join(matchers, ", "))
+		g.printf("\tm.Mock.Helper()\n\treturn m.Mock.On(%q%s)\n}\n\n", method.Name(), args)
+
+		g.printf("// Assert%sCalled asserts that %s was called with matching\n// arguments.\n", method.Name(), method.Name())
+		g.printf("func (m *%s) Assert%sCalled(r mock.Recorder%s) *prettytest.Assertion {\n", mockName, method.Name(), prefixComma(matchers))
+		g.printf("\tm.Mock.Helper()\n\treturn m.Mock.AssertCalled(r, %q%s)\n}\n\n", method.Name(), args)
+	}
+	return nil
+}
+
+func prefixComma(list []string) string {
+	if len(list) == 0 {
+		return ""
+	}
+	return ", " + strings.Join(list, ", ")
+}
+
+// newGenerator returns a generator of the mocks of the interfaces of
+// pkg, written to a file of the named package or, if packageName is
+// empty, of pkg itself.
+func newGenerator(pkg *types.Package, packageName string) (*generator, error) {
+	g := &generator{pkg: pkg, name: pkg.Name(), path: pkg.Path(), imports: make(map[string]string)}
+	if packageName != "" && packageName != pkg.Name() {
+		if strings.HasPrefix(pkg.Path(), ".") {
+			return nil, fmt.Errorf("not an import path, which is needed with -package")
+		}
+		g.name, g.path = packageName, ""
+	}
+	return g, nil
+}
+
+// generate returns the source of the mocks of the named interfaces,
+// all the exported ones if names is empty.
+func (g *generator) generate(names []string) ([]byte, error) {
+	objs, err := g.interfaces(names)
+	if err != nil {
+		return nil, err
+	}
+	g.imports[mockImportPath] = "mock"
+	g.imports[prettytestImportPath] = "prettytest"
+	for _, obj := range objs {
+		if err := g.generateMock(obj); err != nil {
+			return nil, err
+		}
+	}
+
+	var header bytes.Buffer
+	fmt.Fprintf(&header, "// Code generated by ptmock. DO NOT EDIT.\n\npackage %s\n\nimport (\n", g.name)
+	paths := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fmt.Fprintf(&header, "\t%q\n", path)
+	}
+	fmt.Fprintf(&header, ")\n\n")
+	return format.Source(append(header.Bytes(), g.buf.Bytes()...))
+}
+
+func main() {
+	output := flag.String("o", "", "Output file (default stdout)")
+	packageName := flag.String("package", "", "Package of the generated code (default the package of the interfaces)")
+	help := flag.Bool("help", false, "Show usage")
+	flag.Usage = usage
+	flag.Parse()
+
+	if *help || flag.NArg() < 1 {
+		usage()
+		return
+	}
+
+	dir, err := os.Getwd()
+	if err != nil {
+		log.Fatal(err)
+	}
+	fset := token.NewFileSet()
+	imp := importer.ForCompiler(fset, "source", nil).(types.ImporterFrom)
+	pkg, err := imp.ImportFrom(flag.Arg(0), dir, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	g, err := newGenerator(pkg, *packageName)
+	if err != nil {
+		log.Fatalf("%s: %s", flag.Arg(0), err)
+	}
+	src, err := g.generate(flag.Args()[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *output == "" {
+		os.Stdout.Write(src)
+		return
+	}
+	if err := ioutil.WriteFile(*output, src, 0644); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "Update the golden files")
+
+const samplePath = "./testdata/sample"
+
+func importSample(t *testing.T, fset *token.FileSet) *types.Package {
+	dir, err := filepath.Abs(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	imp := importer.ForCompiler(fset, "source", nil).(types.ImporterFrom)
+	pkg, err := imp.ImportFrom(samplePath, dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pkg
+}
+
+func TestGolden(t *testing.T) {
+	fset := token.NewFileSet()
+	g, err := newGenerator(importSample(t, fset), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	src, err := g.generate([]string{"Store", "Caller"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	golden := filepath.Join("testdata", "sample.golden")
+	if *update {
+		if err := ioutil.WriteFile(golden, src, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := ioutil.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(src, want) {
+		t.Errorf("Generated code differs from %s, run go test -update to update it:\n%s", golden, src)
+	}
+
+	// The generated code must compile along with the sample package
+	// and implement its interfaces.
+	files := []*ast.File{}
+	for _, name := range []string{filepath.Join(samplePath, "sample.go"), "mocks.go"} {
+		var content interface{}
+		if name == "mocks.go" {
+			content = src
+		}
+		f, err := parser.ParseFile(fset, name, content, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files = append(files, f)
+	}
+	conf := types.Config{Importer: importer.ForCompiler(fset, "source", nil)}
+	pkg, err := conf.Check("sample", fset, files, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"Store", "Caller"} {
+		iface := pkg.Scope().Lookup(name).Type().Underlying().(*types.Interface)
+		mock := types.NewPointer(pkg.Scope().Lookup("Mock" + name).Type())
+		if !types.Implements(mock, iface) {
+			t.Errorf("Mock%s doesn't implement %s", name, name)
+		}
+	}
+}
+
+func TestClashes(t *testing.T) {
+	fset := token.NewFileSet()
+	pkg := importSample(t, fset)
+	for name, want := range map[string]string{
+		"Clashing":  "Clashing.OnSave and Clashing.Save both need a method OnSave",
+		"Embedding": "Embedding.Mock clashes with the mock.Mock field",
+	} {
+		g, err := newGenerator(pkg, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := g.generate([]string{name}); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected error %q generating Mock%s, got %v", want, name, err)
+		}
+	}
+}
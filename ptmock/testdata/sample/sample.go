@@ -0,0 +1,42 @@
+// Package sample declares the interfaces mocked by the tests of
+// ptmock.
+package sample
+
+import (
+	"io"
+	"net/http"
+)
+
+type Item struct {
+	Name string
+}
+
+// Store covers named, unnamed, variadic and cross-package parameters,
+// and parameters named after the identifiers of the generated code.
+type Store interface {
+	Get(key string) (string, error)
+	Put(string, interface{})
+	Reader(r0 int) io.Reader
+	Log(format string, args ...interface{})
+	Copy(io io.Writer, a0 string) (int64, error)
+	Handle(m http.ResponseWriter, r *http.Request)
+	Find(Item string, error bool) (Item, error)
+}
+
+// Caller has methods named after those of mock.Mock.
+type Caller interface {
+	On(event string) bool
+	Called() int
+	Helper()
+}
+
+// Clashing needs a mock with two methods named OnSave.
+type Clashing interface {
+	Save()
+	OnSave()
+}
+
+// Embedding has a method named after the field embedding mock.Mock.
+type Embedding interface {
+	Mock() bool
+}
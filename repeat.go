@@ -0,0 +1,91 @@
+package prettytest
+
+// untilFailLimit bounds the number of runs of -pt.until-fail when
+// -pt.count is not given.
+const untilFailLimit = 100
+
+// RunStats holds the outcomes of a test method run more than once,
+// either because of -pt.count/-pt.until-fail or because it was
+// retried (see -pt.retries).
+type RunStats struct {
+	Suite, Name    string
+	Runs, Failures int
+	Retries        int
+	Flaky          bool
+}
+
+func failed(testFunc *TestFunc) bool {
+	return testFunc.Status == STATUS_FAIL || testFunc.Status == STATUS_TIMEOUT
+}
+
+// runs returns how many times each test should be run.
+func runs() int {
+	if *untilFail && *repeatCount <= 1 {
+		return untilFailLimit
+	}
+	if *repeatCount < 1 {
+		return 1
+	}
+	return *repeatCount
+}
+
+// discardErrors removes the errors of testFunc from the error log.
+func discardErrors(testFunc *TestFunc) {
+	errors := ErrorLog[:0]
+	for _, error := range ErrorLog {
+		if error.TestFunc != testFunc {
+			errors = append(errors, error)
+		}
+	}
+	ErrorLog = errors
+}
+
+// runAttempts runs a test retrying it up to -pt.retries times while
+// it fails. The errors of the failed attempts are discarded.
+func (plan *suitePlan) runAttempts(tc *testCase, tags []string) *TestFunc {
+	testFunc := plan.runTest(tc, tags)
+	for retries := 1; failed(testFunc) && retries <= *maxRetries; retries++ {
+		discardErrors(testFunc)
+		testFunc = plan.runTest(tc, tags)
+		testFunc.Retries = retries
+	}
+	return testFunc
+}
+
+// runRepeated runs a test as many times as requested by -pt.count
+// and -pt.until-fail and aggregates the outcomes. A test that both
+// passed and failed is marked as flaky.
+func (plan *suitePlan) runRepeated(tc *testCase, tags []string, report *FinalReport) *TestFunc {
+	var testFunc *TestFunc
+	n, failures, retries := runs(), 0, 0
+	i := 0
+	for i < n {
+		testFunc = plan.runAttempts(tc, tags)
+		i++
+		retries += testFunc.Retries
+		if testFunc.filtered || testFunc.Status == STATUS_SKIPPED {
+			return testFunc
+		}
+		if failed(testFunc) {
+			failures++
+			if *untilFail {
+				break
+			}
+		}
+	}
+	testFunc.Runs, testFunc.Failures = i, failures
+	if failures > 0 && failures < i {
+		testFunc.Status = STATUS_FLAKY
+	}
+	if i > 1 || retries > 0 {
+		report.RunStats = append(report.RunStats, &RunStats{
+			Suite:    plan.catcher.suite().Name,
+			Name:     testFunc.Name,
+			Runs:     i,
+			Failures: failures,
+			Retries:  retries,
+			Flaky:    testFunc.Status == STATUS_FLAKY,
+		})
+	}
+	return testFunc
+}
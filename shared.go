@@ -0,0 +1,120 @@
+package prettytest
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+)
+
+// Shared is embedded in shared examples: suite fragments whose test
+// methods are included in several suites. A suite includes a fragment
+// through a named exported field, usually parameterized by an
+// interface value set in BeforeAll or Before:
+//
+//	type stackExamples struct {
+//		prettytest.Shared
+//		Stack Stack
+//	}
+//
+//	func (e *stackExamples) TestPush() {
+//		e.Stack.Push(1)
+//		e.Equal(1, e.Stack.Len())
+//	}
+//
+//	type sliceStackSuite struct {
+//		prettytest.Suite
+//		Examples stackExamples
+//	}
+//
+//	func (s *sliceStackSuite) Before() {
+//		s.Examples.Stack = new(sliceStack)
+//	}
+//
+// The tests of the fragment are run with the hooks of the including
+// suite and reported under its name as "Examples.TestPush". Before
+// running them, the Suite of the fragment is set to the including
+// suite, so that its assertions are recorded there. Nil pointer
+// fields are allocated. Fragments embedded anonymously have their
+// methods promoted and run as methods of the suite.
+type Shared struct {
+	*Suite
+}
+
+var sharedType = reflect.TypeOf(Shared{})
+
+// sharedIndex returns the index of the Shared field of t, or of the
+// struct t points to, if any.
+func sharedIndex(t reflect.Type) (int, bool) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return 0, false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		if field := t.Field(i); field.Anonymous && field.Type == sharedType {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// collectShared binds the shared examples included by the suite and
+// adds their test methods matching pattern, recording misconfigured
+// fields as plan errors.
+func (plan *suitePlan) collectShared(pattern *regexp.Regexp) {
+	v := reflect.ValueOf(plan.catcher)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return
+	}
+	v = v.Elem()
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		index, ok := sharedIndex(field.Type)
+		if !ok {
+			continue
+		}
+		fv := v.Field(i)
+		if !fv.CanSet() {
+			if !field.Anonymous {
+				plan.addError(field.Name, "", 0, fmt.Sprintf("Shared examples field %s must be exported", field.Name))
+			} else if fv.Kind() == reflect.Ptr && fv.IsNil() {
+				plan.addError(field.Name, "", 0, fmt.Sprintf("Shared examples field %s must be exported or not nil", field.Name))
+			} else {
+				plan.bindShared(fv, index)
+			}
+			continue
+		}
+		if fv.Kind() == reflect.Ptr && fv.IsNil() {
+			fv.Set(reflect.New(field.Type.Elem()))
+		}
+		receiver := fv
+		if fv.Kind() != reflect.Ptr {
+			receiver = fv.Addr()
+		}
+		plan.bindShared(receiver, index)
+		if field.Anonymous {
+			continue
+		}
+		for j := 0; j < receiver.NumMethod(); j++ {
+			method := receiver.Type().Method(j)
+			name := field.Name + "." + method.Name
+			focused := focusedMethod(pattern, method.Name)
+			if hookNames[method.Name] || !(focused || pattern.MatchString(method.Name)) || !filterMethod(plan.catcher.suite().Name, name) {
+				continue
+			}
+			if plan.validate(method, "Test method", "func()") {
+				plan.methods = append(plan.methods, &testCase{name: name, method: method, receiver: receiver, focused: focused})
+			}
+		}
+	}
+}
+
+// bindShared points the Shared field at index of the fragment v to
+// the suite.
+func (plan *suitePlan) bindShared(v reflect.Value, index int) {
+	if v.Kind() == reflect.Ptr {
+		v = v.Elem()
+	}
+	v.Field(index).Field(0).Set(reflect.ValueOf(plan.catcher.suite()))
+}
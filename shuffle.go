@@ -0,0 +1,38 @@
+package prettytest
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"time"
+)
+
+// shuffleSeed parses -pt.shuffle. It returns the seed to use and
+// whether the tests should be shuffled at all.
+func shuffleSeed() (int64, bool, error) {
+	switch *shuffle {
+	case "", "off":
+		return 0, false, nil
+	case "on":
+		return time.Now().UnixNano(), true, nil
+	}
+	seed, err := strconv.ParseInt(*shuffle, 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("Invalid -pt.shuffle value %q, expected on, off or a seed", *shuffle)
+	}
+	return seed, true, nil
+}
+
+// shufflePlans randomizes the order of the suites and of the tests
+// inside each suite. The same seed always gives the same order.
+func shufflePlans(plans []*suitePlan, seed int64) {
+	r := rand.New(rand.NewSource(seed))
+	r.Shuffle(len(plans), func(i, j int) {
+		plans[i], plans[j] = plans[j], plans[i]
+	})
+	for _, plan := range plans {
+		r.Shuffle(len(plan.methods), func(i, j int) {
+			plan.methods[i], plan.methods[j] = plan.methods[j], plan.methods[i]
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package prettytest
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+)
+
+// specPrefix is the prefix of the methods declaring specs with
+// Describe and It.
+const specPrefix = "Spec"
+
+// specContainer is a Describe block being declared.
+type specContainer struct {
+	parent                *specContainer
+	path                  []string
+	beforeEach, afterEach []func()
+	focused               bool
+}
+
+// isFocused checks if c or one of the blocks enclosing it is
+// focused.
+func (c *specContainer) isFocused() bool {
+	return c != nil && (c.focused || c.parent.isFocused())
+}
+
+func (c *specContainer) runBeforeEach() {
+	if c == nil {
+		return
+	}
+	c.parent.runBeforeEach()
+	for _, fn := range c.beforeEach {
+		fn()
+	}
+}
+
+func (c *specContainer) runAfterEach() {
+	if c == nil {
+		return
+	}
+	for i := len(c.afterEach) - 1; i >= 0; i-- {
+		c.afterEach[i]()
+	}
+	c.parent.runAfterEach()
+}
+
+// declaring returns the Describe block being declared, panicking if
+// called outside of a Spec method.
+func (s *Suite) declaring(what string) *specContainer {
+	if s.specs == nil {
+		panic(fmt.Sprintf("%s must be called while declaring specs in a %s method", what, specPrefix))
+	}
+	return s.specs
+}
+
+// Describe declares a group of specs about text. body declares the
+// specs with It and, optionally, nested groups with Describe or With and the
+// functions to run around each of them with BeforeEach and AfterEach.
+// Describe must be called from a method whose name starts with the
+// word Spec (e.g. SpecParser or Spec_parser, but not Special), which
+// the runner calls before running the suite:
+//
+//	func (s *parserSuite) SpecParser() {
+//		s.Describe("Parser", func() {
+//			var p *Parser
+//			s.BeforeEach(func() { p = NewParser() })
+//			s.With("empty input", func() {
+//				s.It("returns EOF", func() {
+//					s.Equal(EOF, p.Parse(""))
+//				})
+//			})
+//		})
+//	}
+//
+// Specs are reported as "Parser/with empty input/returns EOF" and
+// rendered as a tree by BDDFormatter.
+func (s *Suite) Describe(text string, body func()) {
+	s.describe("Describe", text, body, false)
+}
+
+// With declares a group of specs like Describe, prefixing text with
+// "with", e.g. s.With("empty input", ...) declares the group "with
+// empty input". It plays the role of the Context of other BDD
+// libraries, since Suite.Context returns the context of the running
+// test.
+func (s *Suite) With(text string, body func()) {
+	s.describe("With", "with "+text, body, false)
+}
+
+// FDescribe declares a group of focused specs: when some tests of
+// the run are focused, only those are run.
+func (s *Suite) FDescribe(text string, body func()) {
+	s.describe("FDescribe", text, body, true)
+}
+
+func (s *Suite) describe(what, text string, body func(), focused bool) {
+	parent := s.declaring(what)
+	path := append(append([]string(nil), parent.path...), text)
+	s.specs = &specContainer{parent: parent, path: path, focused: focused}
+	defer func() { s.specs = parent }()
+	body()
+}
+
+// BeforeEach declares a function run before each spec of the
+// enclosing Describe, after those declared by the outer ones.
+func (s *Suite) BeforeEach(fn func()) {
+	c := s.declaring("BeforeEach")
+	c.beforeEach = append(c.beforeEach, fn)
+}
+
+// AfterEach declares a function run after each spec of the enclosing
+// Describe, before those declared by the outer ones. It is run even
+// if the spec fails.
+func (s *Suite) AfterEach(fn func()) {
+	c := s.declaring("AfterEach")
+	c.afterEach = append(c.afterEach, fn)
+}
+
+// It declares a spec described by text.
+func (s *Suite) It(text string, body func()) {
+	s.it("It", text, body, false)
+}
+
+// FIt declares a focused spec: when some tests of the run are
+// focused, only those are run.
+func (s *Suite) FIt(text string, body func()) {
+	s.it("FIt", text, body, true)
+}
+
+// XIt declares a pending spec, whose body is not run.
+func (s *Suite) XIt(text string, body func()) {
+	s.it("XIt", text, nil, false)
+}
+
+func (s *Suite) it(what, text string, body func(), focused bool) {
+	c := s.declaring(what)
+	_, filename, line, _ := runtime.Caller(2)
+	tc := &testCase{
+		name:     strings.Join(append(append([]string(nil), c.path...), text), "/"),
+		path:     c.path,
+		filename: filename,
+		line:     line,
+		focused:  focused || c.isFocused(),
+	}
+	if body == nil {
+		tc.fn = s.Pending
+	} else {
+		tc.fn = func() {
+			defer c.runAfterEach()
+			c.runBeforeEach()
+			body()
+		}
+	}
+	s.declared = append(s.declared, tc)
+}
+
+// collectSpecs calls the Spec methods of the suite to declare its
+// specs, adding those accepted by filterMethod.
+func (plan *suitePlan) collectSpecs() {
+	s := plan.catcher.suite()
+	for _, h := range plan.specMethods {
+		s.specs, s.declared = new(specContainer), nil
+		if r := protect(func() { h.fn.Call([]reflect.Value{reflect.ValueOf(plan.catcher)}) }); r != nil {
+			plan.addError(h.name, r.filename, r.line, r.message())
+		}
+		for _, tc := range s.declared {
+			if filterMethod(s.Name, tc.name) {
+				plan.methods = append(plan.methods, tc)
+			}
+		}
+	}
+	s.specs, s.declared = nil, nil
+}
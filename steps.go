@@ -0,0 +1,91 @@
+package prettytest
+
+import "fmt"
+
+// Step is a step of a scenario, declared with Given, When, Then or
+// And. Its status is STATUS_PASS, STATUS_FAIL, STATUS_PENDING, or
+// STATUS_SKIPPED when it isn't run because a previous step failed or
+// is pending.
+type Step struct {
+	Keyword, Text string
+	Status        int
+}
+
+// Given runs fn as a step setting up the context of a scenario.
+// Scenarios are test methods made of steps:
+//
+//	func (s *cartSuite) TestCheckout() {
+//		var cart Cart
+//		s.Given("a cart with two items", func() {
+//			cart.Add("book")
+//			cart.Add("pen")
+//		})
+//		s.When("the customer checks out", func() {
+//			s.Nil(cart.Checkout())
+//		})
+//		s.Then("the cart is empty", func() {
+//			s.Equal(0, cart.Len())
+//		})
+//	}
+//
+// A step fails when it makes a failed assertion, and the steps
+// following it are not run. A nil fn declares a pending step.
+func (s *Suite) Given(text string, fn func()) {
+	s.step("Given", text, fn)
+}
+
+// When runs fn as a step performing the action of a scenario.
+func (s *Suite) When(text string, fn func()) {
+	s.step("When", text, fn)
+}
+
+// Then runs fn as a step checking the outcome of a scenario.
+func (s *Suite) Then(text string, fn func()) {
+	s.step("Then", text, fn)
+}
+
+// And runs fn as a step continuing the previous one.
+func (s *Suite) And(text string, fn func()) {
+	s.step("And", text, fn)
+}
+
+func failedAssertions(testFunc *TestFunc) (n int) {
+	for _, assertion := range testFunc.Assertions {
+		if !assertion.Passed {
+			n++
+		}
+	}
+	return n
+}
+
+func (s *Suite) step(keyword, text string, fn func()) {
+	testFunc := s.current()
+	if testFunc == nil {
+		panic(fmt.Sprintf("%s must be called from a running test", keyword))
+	}
+	step := &Step{Keyword: keyword, Text: text, Status: STATUS_SKIPPED}
+	testFunc.Steps = append(testFunc.Steps, step)
+	for _, previous := range testFunc.Steps[:len(testFunc.Steps)-1] {
+		if previous.Status != STATUS_PASS {
+			return
+		}
+	}
+	if fn == nil {
+		step.Status = STATUS_PENDING
+		testFunc.Status = STATUS_PENDING
+		return
+	}
+
+	before, done := failedAssertions(testFunc), false
+	defer func() {
+		switch {
+		case !done && testFunc.Status == STATUS_SKIPPED:
+		case !done || failedAssertions(testFunc) > before:
+			step.Status = STATUS_FAIL
+		default:
+			step.Status = STATUS_PASS
+		}
+	}()
+	fn()
+	done = true
+}
@@ -0,0 +1,214 @@
+package prettytest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Tagged is implemented by suites that tag their test methods. The
+// returned map goes from method name to tags; tags associated with
+// the empty name apply to every test of the suite.
+type Tagged interface {
+	Tags() map[string][]string
+}
+
+// Tag adds tags to the current test function or, when called from
+// BeforeAll, to every test of the suite. Tests are matched against
+// -pt.tags and -pt.skip-tags right before they run, so tags added
+// from within a test method can only exclude it; a test excluded
+// this way is stopped and not reported.
+func (s *Suite) Tag(tags ...string) {
+	current := s.current()
+	if current == nil {
+		s.tags = append(s.tags, tags...)
+		return
+	}
+	current.Tags = mergeTags(current.Tags, tags)
+	if ok, _ := matchTags(current.Tags); !ok {
+		current.filtered = true
+		panic(skipSignal{})
+	}
+}
+
+func mergeTags(tags ...[]string) []string {
+	set := make(map[string]bool)
+	for _, list := range tags {
+		for _, tag := range list {
+			set[tag] = true
+		}
+	}
+	result := make([]string, 0, len(set))
+	for tag := range set {
+		result = append(result, tag)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// matchTags reports whether a test with the given tags is selected
+// by the -pt.tags and -pt.skip-tags expressions.
+func matchTags(tags []string) (bool, error) {
+	set := make(map[string]bool)
+	for _, tag := range tags {
+		set[tag] = true
+	}
+	if *tagsToRun != "" {
+		expr, err := parseTagExpr(*tagsToRun)
+		if err != nil {
+			return false, err
+		}
+		if !expr.eval(set) {
+			return false, nil
+		}
+	}
+	if *tagsToSkip != "" {
+		expr, err := parseTagExpr(*tagsToSkip)
+		if err != nil {
+			return false, err
+		}
+		if expr.eval(set) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// tagExpr is a boolean expression over tags, like
+// "integration && !slow". Operators are !, &&, || (or a comma) and
+// parentheses.
+type tagExpr interface {
+	eval(tags map[string]bool) bool
+}
+
+type tagIdent string
+type tagNot struct{ x tagExpr }
+type tagAnd struct{ x, y tagExpr }
+type tagOr struct{ x, y tagExpr }
+
+func (e tagIdent) eval(tags map[string]bool) bool { return tags[string(e)] }
+func (e tagNot) eval(tags map[string]bool) bool   { return !e.x.eval(tags) }
+func (e tagAnd) eval(tags map[string]bool) bool   { return e.x.eval(tags) && e.y.eval(tags) }
+func (e tagOr) eval(tags map[string]bool) bool    { return e.x.eval(tags) || e.y.eval(tags) }
+
+type tagParser struct {
+	tokens []string
+	pos    int
+}
+
+func parseTagExpr(text string) (tagExpr, error) {
+	tokens, err := tokenizeTagExpr(text)
+	if err != nil {
+		return nil, err
+	}
+	p := &tagParser{tokens: tokens}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q in tag expression %q", p.tokens[p.pos], text)
+	}
+	return expr, nil
+}
+
+func tokenizeTagExpr(text string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(text); {
+		c := rune(text[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '!' || c == '(' || c == ')' || c == ',':
+			tokens = append(tokens, string(c))
+			i++
+		case strings.HasPrefix(text[i:], "&&") || strings.HasPrefix(text[i:], "||"):
+			tokens = append(tokens, text[i:i+2])
+			i += 2
+		case isTagChar(c):
+			j := i
+			for j < len(text) && isTagChar(rune(text[j])) {
+				j++
+			}
+			tokens = append(tokens, text[i:j])
+			i = j
+		default:
+			return nil, fmt.Errorf("unexpected %q in tag expression %q", c, text)
+		}
+	}
+	return tokens, nil
+}
+
+func isTagChar(c rune) bool {
+	return unicode.IsLetter(c) || unicode.IsDigit(c) || strings.ContainsRune("_-.:/", c)
+}
+
+func (p *tagParser) next() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *tagParser) parseOr() (tagExpr, error) {
+	x, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.next() == "||" || p.next() == "," {
+		p.pos++
+		y, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		x = tagOr{x, y}
+	}
+	return x, nil
+}
+
+func (p *tagParser) parseAnd() (tagExpr, error) {
+	x, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.next() == "&&" {
+		p.pos++
+		y, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		x = tagAnd{x, y}
+	}
+	return x, nil
+}
+
+func (p *tagParser) parseUnary() (tagExpr, error) {
+	switch token := p.next(); token {
+	case "":
+		return nil, fmt.Errorf("unexpected end of tag expression")
+	case "!":
+		p.pos++
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return tagNot{x}, nil
+	case "(":
+		p.pos++
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("missing ) in tag expression")
+		}
+		p.pos++
+		return x, nil
+	case ")", "&&", "||", ",":
+		return nil, fmt.Errorf("unexpected %q in tag expression", token)
+	default:
+		p.pos++
+		return tagIdent(token), nil
+	}
+}
@@ -0,0 +1,66 @@
+package prettytest
+
+import (
+	"fmt"
+	"time"
+)
+
+// Timed is implemented by suites that override the -pt.timeout
+// default for some of their test methods. The returned map goes from
+// method name to timeout; the timeout associated with the empty name
+// applies to every test of the suite. A zero timeout disables the
+// limit.
+type Timed interface {
+	Timeouts() map[string]time.Duration
+}
+
+// timeout returns the time limit for the named test.
+func (plan *suitePlan) timeout(name string) time.Duration {
+	if d, ok := plan.timeouts[name]; ok {
+		return d
+	}
+	if d, ok := plan.timeouts[""]; ok {
+		return d
+	}
+	return *testTimeout
+}
+
+// runGuarded calls fn in a new goroutine and waits for it to return,
+// for the timeout to expire (if not zero) or for the run to be
+// interrupted. In the last two cases the test is marked as timed out
+// or failed, the stack of the goroutine is logged and the goroutine
+// is abandoned: test code should watch Suite.Context() to stop in
+// time, since the assertions made by the goroutine, or by those it
+// started, after the runner has moved on are discarded.
+func runGuarded(testFunc *TestFunc, timeout time.Duration, filename string, line int, fn func()) *recovered {
+	s := testFunc.suite
+	done := make(chan *recovered, 1)
+	id := make(chan string, 1)
+	go func() {
+		goroutine := s.bind(testFunc)
+		defer s.unbind(goroutine)
+		id <- goroutine
+		done <- protect(fn)
+	}()
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case r := <-done:
+		return r
+	case <-expired:
+		s.abandon(testFunc)
+		stack := trimStack([]byte(goroutineStacks()[<-id]))
+		testFunc.cancel()
+		testFunc.failWith(filename, line, fmt.Sprintf("Test timed out after %s\n%s", timeout, stack))
+		testFunc.Status = STATUS_TIMEOUT
+	case <-testFunc.ctx.Done():
+		s.abandon(testFunc)
+		stack := trimStack([]byte(goroutineStacks()[<-id]))
+		testFunc.failWith(filename, line, fmt.Sprintf("Test interrupted\n%s", stack))
+	}
+	return nil
+}